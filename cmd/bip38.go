@@ -0,0 +1,32 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// bip38Cmd represents the bip38 command
+var bip38Cmd = &cobra.Command{
+	Use:   "bip38",
+	Short: "Encrypt or decrypt private keys using passphrase",
+	Long: `This command groups subcommands for passphrase
+protected private keys (BIP-38)`,
+}
+
+func init() {
+	rootCmd.AddCommand(bip38Cmd)
+}
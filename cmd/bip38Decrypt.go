@@ -0,0 +1,39 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// bip38DecryptCmd represents the bip38 decrypt command
+var bip38DecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt BIP-38 encrypted private key using passphrase",
+	Long: `This command decrypts a BIP-38 encrypted private key,
+EC-multiplied or not, and decodes the private wif key`,
+	RunE: run.Bip38Decrypt,
+	Args: cobra.MaximumNArgs(1),
+}
+
+func init() {
+	bip38Cmd.AddCommand(bip38DecryptCmd)
+	f := bip38DecryptCmd.Flags()
+
+	f.String(flags.Network, flags.NetworkMainnet, "Network: mainnet, testnet, signet or regtest")
+}
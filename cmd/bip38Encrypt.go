@@ -0,0 +1,47 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// bip38EncryptCmd represents the bip38 encrypt command
+var bip38EncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt private wif key using passphrase",
+	Long: `This command encrypts a private wif key using passphrase
+without EC multiplication. With --ec-multiply a new private key is
+generated and encrypted using EC multiplication instead. Such keys can
+also be generated by a third party using an intermediate code without
+knowing the passphrase`,
+	RunE: run.Bip38Encrypt,
+	Args: cobra.MaximumNArgs(1),
+}
+
+func init() {
+	bip38Cmd.AddCommand(bip38EncryptCmd)
+	f := bip38EncryptCmd.Flags()
+
+	f.Bool(flags.EcMultiply, false, "Generate new EC-multiplied encrypted key using passphrase")
+	f.String(flags.IntermediateCode, "", "Generate new EC-multiplied encrypted key using intermediate code")
+	f.Uint32(flags.Lot, 0, "Lot number encoded in intermediate code")
+	f.Uint32(flags.Sequence, 0, "Sequence number encoded in intermediate code")
+	f.String(flags.Network, flags.NetworkMainnet, "Network: mainnet, testnet, signet or regtest")
+	f.Bool(flags.UncompressedWif, false, "Generate EC-multiplied key with uncompressed pub key")
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert extended key version such as xpub to zpub",
+	Long: `This command serializes an extended key using another
+SLIP-132 key version, such as xpub, ypub or zpub, without changing
+the key material. Conversion is only allowed within the same key
+class and network and a warning is shown when the conversion changes
+the script type implied by the key version`,
+	RunE: run.Convert,
+	Args: cobra.MaximumNArgs(1),
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	f := convertCmd.Flags()
+
+	f.String(flags.To, "", "Target key version such as xpub, ypub, zpub")
+
+	_ = convertCmd.MarkFlagRequired(flags.To)
+
+	_ = convertCmd.RegisterFlagCompletionFunc(
+		flags.To,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return keys.VersionNames(),
+				cobra.ShellCompDirectiveDefault
+		},
+	)
+}
@@ -16,6 +16,8 @@ limitations under the License.
 package cmd
 
 import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
 	"github.com/kubetrail/bip32/pkg/run"
 	"github.com/spf13/cobra"
 )
@@ -31,4 +33,64 @@ var decodeCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(decodeCmd)
+	f := decodeCmd.Flags()
+
+	f.String(flags.Network, flags.NetworkMainnet, "Network for public hex keys: mainnet, testnet, signet or regtest")
+	f.String(flags.AddrType, keys.AddrTypeP2pkhOrP2sh, "Script type of addr for public hex keys")
+	f.String(flags.Coin, "", "Coin for extended keys sharing versions, such as eth or bch xpub, detected from key as btc by default")
+
+	_ = decodeCmd.RegisterFlagCompletionFunc(
+		flags.Coin,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return keys.CoinNames(),
+				cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	_ = decodeCmd.RegisterFlagCompletionFunc(
+		flags.Network,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return []string{
+					flags.NetworkMainnet,
+					flags.NetworkTestnet,
+					flags.NetworkSignet,
+					flags.NetworkRegtest,
+				},
+				cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	_ = decodeCmd.RegisterFlagCompletionFunc(
+		flags.AddrType,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return []string{
+					keys.AddrTypeP2pkhOrP2sh,
+					keys.AddrTypeP2wpkhP2sh,
+					keys.AddrTypeP2wpkh,
+					keys.AddrTypeP2tr,
+				},
+				cobra.ShellCompDirectiveDefault
+		},
+	)
 }
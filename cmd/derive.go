@@ -6,6 +6,7 @@ package cmd
 
 import (
 	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
 	"github.com/kubetrail/bip32/pkg/run"
 	"github.com/spf13/cobra"
 )
@@ -25,4 +26,30 @@ func init() {
 	f := deriveCmd.Flags()
 
 	f.String(flags.DerivationPath, "m", "Relative chain Derivation path")
+	f.String(flags.Network, "", "Network for keys sharing versions, such as signet or regtest, detected from key by default")
+	f.String(flags.Coin, "", "Coin for keys sharing versions, such as eth or bch xpub, detected from key as btc by default. Output descriptors are only produced for btc")
+	f.String(flags.WitnessScript, "", "Multisig witness script hex for a single p2wsh address")
+	f.Int(flags.MultisigThreshold, 0, "Required signatures for sorted multisig p2wsh address types")
+	f.StringSlice(flags.MultisigPubKeys, nil, "Cosigner pub keys (hex or extended with optional key origin) for sorted multisig")
+	f.Uint32(flags.StartIndex, 0, "Start index for wildcard in derivation path")
+	f.Uint32(flags.Count, 1, "Number of indices for wildcard in derivation path")
+	f.Bool(flags.UncompressedWif, false, "Emit uncompressed WIF and legacy address of uncompressed pub key")
+	f.Bool(flags.EncryptWif, false, "Emit BIP-38 passphrase encrypted private key instead of WIF")
+	f.String(flags.FromKeystore, "", "Read extended private key from named keystore entry")
+	f.String(flags.KeystoreDir, "", "Keystore dir, defaults to $HOME/.bip32/keystore")
+
+	_ = deriveCmd.RegisterFlagCompletionFunc(
+		flags.Coin,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return keys.CoinNames(),
+				cobra.ShellCompDirectiveDefault
+		},
+	)
 }
@@ -0,0 +1,40 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// descriptorCmd represents the descriptor command
+var descriptorCmd = &cobra.Command{
+	Use:   "descriptor",
+	Short: "Derive addresses from output descriptor",
+	Long: `This command parses an output script descriptor and derives
+its addresses for a range of wildcard indices`,
+	RunE: run.Descriptor,
+	Args: cobra.MaximumNArgs(1),
+}
+
+func init() {
+	rootCmd.AddCommand(descriptorCmd)
+	f := descriptorCmd.Flags()
+
+	f.Uint32(flags.StartIndex, 0, "First wildcard index to derive")
+	f.Uint32(flags.Count, 1, "Number of wildcard indices to derive")
+}
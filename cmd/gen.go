@@ -44,9 +44,19 @@ func init() {
 	f.String(flags.MnemonicLanguage, mnemonics.LanguageEnglish, "Mnemonic language")
 	f.Bool(flags.SkipMnemonicValidation, false, "Skip mnemonic validation")
 	// https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki#serialization-format
-	f.String(flags.Network, flags.NetworkMainnet, "Network: mainnet or testnet")
+	f.String(flags.Network, flags.NetworkMainnet, "Network: mainnet, testnet, signet or regtest")
 	f.String(flags.AddrType, keys.AddrTypeP2pkhOrP2sh, "Script type")
+	f.String(flags.Coin, keys.CoinTypeBtc, "Coin type: btc, ltc, doge, dash, eth or bch, output descriptors are only produced for btc")
 	f.Bool(flags.ShowAllKeys, false, "Show all keys")
+	f.String(flags.WitnessScript, "", "Multisig witness script hex for a single p2wsh address")
+	f.Int(flags.MultisigThreshold, 0, "Required signatures for sorted multisig p2wsh address types")
+	f.StringSlice(flags.MultisigPubKeys, nil, "Cosigner pub keys (hex or extended with optional key origin) for sorted multisig")
+	f.Uint32(flags.StartIndex, 0, "Start index for wildcard in derivation path")
+	f.Uint32(flags.Count, 1, "Number of indices for wildcard in derivation path")
+	f.Bool(flags.UncompressedWif, false, "Emit uncompressed WIF and legacy address of uncompressed pub key")
+	f.Bool(flags.EncryptWif, false, "Emit BIP-38 passphrase encrypted private key instead of WIF")
+	f.String(flags.FromKeystore, "", "Read seed from named keystore entry")
+	f.String(flags.KeystoreDir, "", "Keystore dir, defaults to $HOME/.bip32/keystore")
 
 	_ = genCmd.RegisterFlagCompletionFunc(
 		flags.Network,
@@ -61,11 +71,28 @@ func init() {
 			return []string{
 					flags.NetworkMainnet,
 					flags.NetworkTestnet,
+					flags.NetworkSignet,
+					flags.NetworkRegtest,
 				},
 				cobra.ShellCompDirectiveDefault
 		},
 	)
 
+	_ = genCmd.RegisterFlagCompletionFunc(
+		flags.Coin,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return keys.CoinNames(),
+				cobra.ShellCompDirectiveDefault
+		},
+	)
+
 	_ = genCmd.RegisterFlagCompletionFunc(
 		flags.MnemonicLanguage,
 		func(
@@ -111,6 +138,8 @@ func init() {
 					flags.DerivationPath6,
 					flags.DerivationPath7,
 					flags.DerivationPath8,
+					flags.DerivationPath9,
+					flags.DerivationPath10,
 				},
 				cobra.ShellCompDirectiveDefault
 		},
@@ -136,11 +165,15 @@ func init() {
 					keys.AddrTypeBip44,
 					keys.AddrTypeBip49,
 					keys.AddrTypeBip84,
+					keys.AddrTypeBip86,
+					keys.AddrTypeTaproot,
 					keys.AddrTypeP2pkhOrP2sh,
 					keys.AddrTypeP2wpkhP2sh,
 					keys.AddrTypeP2wshP2sh,
 					keys.AddrTypeP2wpkh,
 					keys.AddrTypeP2wsh,
+					keys.AddrTypeP2tr,
+					keys.AddrTypeEth,
 				},
 				cobra.ShellCompDirectiveDefault
 		},
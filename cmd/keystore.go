@@ -0,0 +1,38 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/spf13/cobra"
+)
+
+// keystoreCmd represents the keystore command
+var keystoreCmd = &cobra.Command{
+	Use:   "keystore",
+	Short: "Manage passphrase encrypted seeds and extended private keys",
+	Long: `This command groups subcommands for a local keystore
+of named seeds and extended private keys encrypted using
+a passphrase. Entries can be used by gen and derive commands
+via --from-keystore flag`,
+}
+
+func init() {
+	rootCmd.AddCommand(keystoreCmd)
+	f := keystoreCmd.PersistentFlags()
+
+	f.String(flags.KeystoreDir, "", "Keystore dir, defaults to $HOME/.bip32/keystore")
+}
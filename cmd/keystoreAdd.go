@@ -0,0 +1,90 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keystore"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/kubetrail/bip39/pkg/mnemonics"
+	"github.com/spf13/cobra"
+)
+
+// keystoreAddCmd represents the keystore add command
+var keystoreAddCmd = &cobra.Command{
+	Use:   "add <name> [mnemonic | seed | xprv]",
+	Short: "Add seed or extended private key to keystore",
+	Long: `This command encrypts seed derived from mnemonic, hex seed
+or extended private key using a passphrase and saves it
+in the keystore under the name`,
+	RunE: run.KeystoreAdd,
+	Args: cobra.RangeArgs(1, 25),
+}
+
+func init() {
+	keystoreCmd.AddCommand(keystoreAddCmd)
+	f := keystoreAddCmd.Flags()
+
+	f.Bool(flags.UsePassphrase, false, "Prompt for secret mnemonic passphrase")
+	f.Bool(flags.InputHexSeed, false, "Treat input as hex seed instead of mnemonic")
+	f.Bool(flags.InputXPrv, false, "Treat input as extended private key instead of mnemonic")
+	f.String(flags.MnemonicLanguage, mnemonics.LanguageEnglish, "Mnemonic language")
+	f.Bool(flags.SkipMnemonicValidation, false, "Skip mnemonic validation")
+	f.String(flags.Cipher, keystore.CipherAesGcm, "Cipher: aes-256-gcm or xchacha20-poly1305")
+
+	_ = keystoreAddCmd.RegisterFlagCompletionFunc(
+		flags.Cipher,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return []string{
+					keystore.CipherAesGcm,
+					keystore.CipherXChaCha20Poly1305,
+				},
+				cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	_ = keystoreAddCmd.RegisterFlagCompletionFunc(
+		flags.MnemonicLanguage,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return []string{
+					mnemonics.LanguageEnglish,
+					mnemonics.LanguageJapanese,
+					mnemonics.LanguageChineseSimplified,
+					mnemonics.LanguageChineseTraditional,
+					mnemonics.LanguageCzech,
+					mnemonics.LanguageFrench,
+					mnemonics.LanguageItalian,
+					mnemonics.LanguageKorean,
+					mnemonics.LanguageSpanish,
+				},
+				cobra.ShellCompDirectiveDefault
+		},
+	)
+}
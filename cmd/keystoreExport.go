@@ -0,0 +1,35 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// keystoreExportCmd represents the keystore export command
+var keystoreExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Decrypt and print keystore entry",
+	Long: `This command decrypts the keystore entry using passphrase
+and prints the seed or extended private key held by it`,
+	RunE: run.KeystoreExport,
+	Args: cobra.ExactArgs(1),
+}
+
+func init() {
+	keystoreCmd.AddCommand(keystoreExportCmd)
+}
@@ -0,0 +1,35 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// keystoreListCmd represents the keystore list command
+var keystoreListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List keystore entries",
+	Long: `This command lists names and types of keystore entries
+without decrypting them`,
+	RunE: run.KeystoreList,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	keystoreCmd.AddCommand(keystoreListCmd)
+}
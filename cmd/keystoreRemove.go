@@ -0,0 +1,35 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// keystoreRemoveCmd represents the keystore remove command
+var keystoreRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove entry from keystore",
+	Long: `This command deletes the keystore entry. Secret held
+by the entry cannot be recovered afterwards`,
+	RunE: run.KeystoreRemove,
+	Args: cobra.ExactArgs(1),
+}
+
+func init() {
+	keystoreCmd.AddCommand(keystoreRemoveCmd)
+}
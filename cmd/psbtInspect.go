@@ -0,0 +1,41 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// psbtInspectCmd represents the psbt inspect command
+var psbtInspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "List psbt inputs and outputs and check key ownership",
+	Long: `This command reads a base64 or binary psbt from STDIN and lists
+its inputs and outputs with amounts and scripts. When an extended key,
+public or private, is provided as arg, inputs and outputs owned by the key
+are reported along with change outputs identified by derivation path`,
+	RunE: run.PsbtInspect,
+	Args: cobra.MaximumNArgs(1),
+}
+
+func init() {
+	psbtCmd.AddCommand(psbtInspectCmd)
+	f := psbtInspectCmd.Flags()
+
+	f.String(flags.Network, flags.NetworkMainnet, "Network for addresses when no key is provided")
+}
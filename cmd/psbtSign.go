@@ -0,0 +1,70 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/psbt"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/kubetrail/bip39/pkg/mnemonics"
+	"github.com/spf13/cobra"
+)
+
+// psbtSignCmd represents the psbt sign command
+var psbtSignCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign psbt inputs using mnemonic, seed or xprv",
+	Long: `This command reads a base64 or binary psbt from STDIN or from a file
+and adds partial signatures for P2PKH, P2SH-P2WPKH and P2WPKH inputs whose
+BIP-32 derivation entries match the key. The key can be a mnemonic, a hex
+seed (with --input-hex-seed) or an extended private key provided as args,
+or a keystore entry. When psbt is read from a file, the key is prompted
+for if not provided as args, similar to gen command.
+Inputs requesting sighash types other than ALL are rejected unless
+allowed using --allow-sighash. Updated psbt is written in the same
+encoding as the input`,
+	RunE: run.PsbtSign,
+	Args: cobra.MaximumNArgs(24),
+}
+
+func init() {
+	psbtCmd.AddCommand(psbtSignCmd)
+	f := psbtSignCmd.Flags()
+
+	f.Bool(flags.UsePassphrase, false, "Prompt for secret passphrase")
+	f.Bool(flags.InputHexSeed, false, "Treat input as hex seed instead of mnemonic")
+	f.String(flags.MnemonicLanguage, mnemonics.LanguageEnglish, "Mnemonic language")
+	f.Bool(flags.SkipMnemonicValidation, false, "Skip mnemonic validation")
+	f.String(flags.FromKeystore, "", "Read seed or extended private key from named keystore entry")
+	f.String(flags.KeystoreDir, "", "Keystore dir, defaults to $HOME/.bip32/keystore")
+	f.String(flags.PsbtFile, "", "Read psbt from file instead of STDIN")
+	f.StringSlice(flags.AllowSighash, nil, "Sighash types other than ALL to sign, such as NONE or SINGLE|ANYONECANPAY")
+
+	_ = psbtSignCmd.RegisterFlagCompletionFunc(
+		flags.AllowSighash,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return psbt.SigHashNames(),
+				cobra.ShellCompDirectiveDefault
+		},
+	)
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 
 	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/run"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -55,7 +56,9 @@ func init() {
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 
-	f.String(flags.OutputFormat, flags.OutputFormatNative, "Output format (native, json, yaml, table)")
+	f.String(flags.OutputFormat, flags.OutputFormatNative, "Output format (native, json, yaml, table, csv, jsonl)")
+	f.StringSlice(flags.Columns, nil, "Columns for table, csv and jsonl output, which are json field names such as derivationPath,addr,pubKeyHex,prvKeyWif")
+	f.Bool(flags.NoHeaders, false, "Do not print headers for table and csv output")
 
 	_ = rootCmd.RegisterFlagCompletionFunc(
 		flags.OutputFormat,
@@ -71,10 +74,27 @@ func init() {
 					flags.OutputFormatNative,
 					flags.OutputFormatJson,
 					flags.OutputFormatYaml,
+					flags.OutputFormatTable,
+					flags.OutputFormatCsv,
+					flags.OutputFormatJsonl,
 				},
 				cobra.ShellCompDirectiveDefault
 		},
 	)
+
+	_ = rootCmd.RegisterFlagCompletionFunc(
+		flags.Columns,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return run.ColumnNames(), cobra.ShellCompDirectiveDefault
+		},
+	)
 }
 
 // initConfig reads in config file and ENV variables if set.
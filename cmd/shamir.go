@@ -0,0 +1,32 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// shamirCmd represents the shamir command
+var shamirCmd = &cobra.Command{
+	Use:   "shamir",
+	Short: "Split and combine seed using Shamir's secret sharing",
+	Long: `This command groups subcommands for splitting seed into
+mnemonic shares and combining them per SLIP-39`,
+}
+
+func init() {
+	rootCmd.AddCommand(shamirCmd)
+}
@@ -0,0 +1,48 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+// shamirCombineCmd represents the shamir combine command
+var shamirCombineCmd = &cobra.Command{
+	Use:   "combine",
+	Short: "Combine SLIP-39 mnemonic shares into seed and generate key",
+	Long: `This command recovers seed from SLIP-39 mnemonic shares provided
+as args, each quoted as a single arg, or read from STDIN one per line.
+Exactly member threshold of shares is required for each of group threshold
+of groups. Recovered seed is used to generate keys similar to gen command.
+Shamir passphrase is not verified and a wrong passphrase recovers a
+different seed`,
+	RunE: run.ShamirCombine,
+}
+
+func init() {
+	shamirCmd.AddCommand(shamirCombineCmd)
+	f := shamirCombineCmd.Flags()
+
+	f.Bool(flags.UseShamirPassphrase, false, "Prompt for shamir passphrase encrypting the seed")
+	f.String(flags.DerivationPath, flags.DerivationPathAuto, "Chain Derivation path")
+	f.String(flags.Network, flags.NetworkMainnet, "Network: mainnet, testnet, signet or regtest")
+	f.String(flags.AddrType, keys.AddrTypeP2pkhOrP2sh, "Script type")
+	f.String(flags.Coin, keys.CoinTypeBtc, "Coin type: btc, ltc, doge, dash, eth or bch, output descriptors are only produced for btc")
+	f.Bool(flags.ShowAllKeys, false, "Show all keys including recovered seed")
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/kubetrail/bip39/pkg/mnemonics"
+	"github.com/spf13/cobra"
+)
+
+// shamirSplitCmd represents the shamir split command
+var shamirSplitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split seed into SLIP-39 mnemonic shares",
+	Long: `This command splits seed from a mnemonic, a hex seed (with
+--input-hex-seed) or a keystore entry into groups of SLIP-39 mnemonic
+shares. Seed is recovered from member threshold of shares in each of
+group threshold of groups. Seed is encrypted with the shamir passphrase,
+which is required for recovery, before splitting. Hex seeds of 16 or
+32 bytes produce shares of 20 or 33 words, while 64 byte seeds from
+BIP-39 mnemonics produce shares of 59 words`,
+	RunE: run.ShamirSplit,
+	Args: cobra.MaximumNArgs(24),
+}
+
+func init() {
+	shamirCmd.AddCommand(shamirSplitCmd)
+	f := shamirSplitCmd.Flags()
+
+	f.Bool(flags.UsePassphrase, false, "Prompt for secret passphrase of mnemonic")
+	f.Bool(flags.InputHexSeed, false, "Treat input as hex seed instead of mnemonic")
+	f.String(flags.MnemonicLanguage, mnemonics.LanguageEnglish, "Mnemonic language")
+	f.Bool(flags.SkipMnemonicValidation, false, "Skip mnemonic validation")
+	f.String(flags.FromKeystore, "", "Read seed from named keystore entry")
+	f.String(flags.KeystoreDir, "", "Keystore dir, defaults to $HOME/.bip32/keystore")
+	f.Int(flags.GroupThreshold, 1, "Number of groups required to recover seed")
+	f.StringSlice(flags.Groups, []string{"2of3"}, "Groups as member threshold of member count, such as 2of3,3of5")
+	f.Int(flags.IterationExponent, 1, "Exponent of passphrase encryption iterations, 10000 x 2^exponent")
+	f.Bool(flags.Extendable, true, "Allow splitting seed again into shares compatible with these shares")
+	f.Bool(flags.UseShamirPassphrase, false, "Prompt for shamir passphrase encrypting the seed")
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2022 kubetrail.io authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip32/pkg/run"
+	"github.com/kubetrail/bip39/pkg/mnemonics"
+	"github.com/spf13/cobra"
+)
+
+// signMessageCmd represents the sign-message command
+var signMessageCmd = &cobra.Command{
+	Use:   "sign-message",
+	Short: "Sign message using BIP-137 or BIP-322 signature",
+	Long: `This command signs a message using a wif private key or a key
+generated from mnemonic or seed and derivation path. The signature
+header encodes the address type per BIP-137 for p2pkh, p2wpkh-p2sh
+and p2wpkh addresses. BIP-322 simple and full signatures additionally
+support p2tr addresses`,
+	RunE: run.SignMessage,
+	Args: cobra.MaximumNArgs(24),
+}
+
+func init() {
+	rootCmd.AddCommand(signMessageCmd)
+	f := signMessageCmd.Flags()
+
+	f.String(flags.Message, "", "Message to sign")
+	f.String(flags.DerivationPath, flags.DerivationPathAuto, "Chain Derivation path")
+	f.Bool(flags.UsePassphrase, false, "Prompt for secret passphrase")
+	f.Bool(flags.InputHexSeed, false, "Treat input as hex seed instead of mnemonic")
+	f.String(flags.MnemonicLanguage, mnemonics.LanguageEnglish, "Mnemonic language")
+	f.Bool(flags.SkipMnemonicValidation, false, "Skip mnemonic validation")
+	f.String(flags.Network, flags.NetworkMainnet, "Network: mainnet, testnet, signet or regtest")
+	f.String(flags.AddrType, keys.AddrTypeP2pkhOrP2sh, "Address type: p2pkh-or-p2sh, p2wpkh-p2sh, p2wpkh or p2tr including aliases")
+	f.String(flags.SignatureFormat, keys.MessageFormatLegacy, "Signature format: legacy (BIP-137), simple or full (BIP-322)")
+
+	_ = signMessageCmd.MarkFlagRequired(flags.Message)
+
+	_ = signMessageCmd.RegisterFlagCompletionFunc(
+		flags.SignatureFormat,
+		func(
+			cmd *cobra.Command,
+			args []string,
+			toComplete string,
+		) (
+			[]string,
+			cobra.ShellCompDirective,
+		) {
+			return []string{
+					keys.MessageFormatLegacy,
+					keys.MessageFormatSimple,
+					keys.MessageFormatFull,
+				},
+				cobra.ShellCompDirectiveDefault
+		},
+	)
+}
@@ -9,21 +9,56 @@ const (
 	MnemonicLanguage       = "mnemonic-language"
 	AddrType               = "addr-type"
 	ShowAllKeys            = "show-all-keys"
+	WitnessScript          = "witness-script"
+	MultisigThreshold      = "multisig-threshold"
+	MultisigPubKeys        = "multisig-pub-keys"
+	StartIndex             = "start-index"
+	Count                  = "count"
+	Message                = "message"
+	Signature              = "signature"
+	Addr                   = "addr"
+	SignatureFormat        = "signature-format"
+	Coin                   = "coin"
+	To                     = "to"
+	UncompressedWif        = "uncompressed-wif"
+	EncryptWif             = "encrypt-wif"
+	IntermediateCode       = "intermediate-code"
+	EcMultiply             = "ec-multiply"
+	Lot                    = "lot"
+	Sequence               = "sequence"
+	KeystoreDir            = "keystore-dir"
+	FromKeystore           = "from-keystore"
+	Cipher                 = "cipher"
+	InputXPrv              = "input-xprv"
+	AllowSighash           = "allow-sighash"
+	PsbtFile               = "psbt-file"
+	GroupThreshold         = "group-threshold"
+	Groups                 = "groups"
+	IterationExponent      = "iteration-exponent"
+	Extendable             = "extendable"
+	UseShamirPassphrase    = "use-shamir-passphrase"
 )
 
 const (
 	OutputFormat = "output-format"
+	Columns      = "columns"
+	NoHeaders    = "no-headers"
 )
 
 const (
 	OutputFormatNative = "native"
 	OutputFormatJson   = "json"
 	OutputFormatYaml   = "yaml"
+	OutputFormatTable  = "table"
+	OutputFormatCsv    = "csv"
+	OutputFormatJsonl  = "jsonl"
 )
 
 const (
 	NetworkMainnet = "mainnet"
 	NetworkTestnet = "testnet"
+	NetworkSignet  = "signet"
+	NetworkRegtest = "regtest"
 )
 
 // BIP-44 format m/purpose'/coinType'/account'/change/addressIndex
@@ -42,4 +77,6 @@ const (
 	DerivationPath6    = "m/44h/0h/0h"
 	DerivationPath7    = "m/49h/0h/0h"
 	DerivationPath8    = "m/84h/0h/0h"
+	DerivationPath9    = "m/86h/0h/0h/0/0"
+	DerivationPath10   = "m/86h/0h/0h"
 )
@@ -7,6 +7,8 @@ const (
 	AddrTypeP2wshP2sh   = "p2wsh-p2sh"    // mainnet: [Ypub, Yprv], testnet: [Upub, Uprv]
 	AddrTypeP2wpkh      = "p2wpkh"        // mainnet: [zpub, zprv], testnet: [vpub, vprv]
 	AddrTypeP2wsh       = "p2wsh"         // mainnet: [Zpub, Zprv], testnet: [Vpub, Vprv]
+	AddrTypeP2tr        = "p2tr"          // mainnet: [xpub, xprv], testnet: [tpub, tprv]
+	AddrTypeEth         = "eth"           // mainnet: [xpub, xprv]
 
 	AddrTypeLegacy           = "legacy"            // same as AddrTypeP2pkhOrP2sh, xpub, xprv etc.
 	AddrTypeP2sh             = "p2sh"              // same as AddrTypeP2wpkhP2sh, ypub, yprv etc.
@@ -17,6 +19,8 @@ const (
 	AddrTypeBip44            = "bip44"             // same as AddrTypeLegacy xpub, xprv etc.
 	AddrTypeBip49            = "bip49"             // same as AddrTypeSegWitCompatible ypub, yprv etc.
 	AddrTypeBip84            = "bip84"             // same as AddrTypeSegWitNative zpub, zprv etc.
+	AddrTypeTaproot          = "taproot"           // same as AddrTypeP2tr xpub, xprv etc.
+	AddrTypeBip86            = "bip86"             // same as AddrTypeP2tr xpub, xprv etc.
 )
 
 // key versions
@@ -42,3 +46,22 @@ const (
 	Vpub = "02575483"
 	Vprv = "02575048"
 )
+
+// key versions of other coins
+// https://github.com/satoshilabs/slips/blob/master/slip-0132.md
+const (
+	ltub = "019da462"
+	ltpv = "019d9cfe"
+	mtub = "01b26ef6"
+	mtpv = "01b26792"
+	ttub = "0436f6e1"
+	ttpv = "0436ef7d"
+	dgub = "02facafd"
+	dgpv = "02fac398"
+	tgub = "0432a9a8"
+	tgpv = "0432a243"
+	drkv = "02fe52f8"
+	drkp = "02fe52cc"
+	drkV = "3a8061a0"
+	drkP = "3a805837"
+)
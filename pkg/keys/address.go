@@ -0,0 +1,194 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// script types of decoded addresses
+const (
+	ScriptTypeP2pkh          = "p2pkh"
+	ScriptTypeP2sh           = "p2sh"
+	ScriptTypeP2wpkh         = "p2wpkh"
+	ScriptTypeP2wsh          = "p2wsh"
+	ScriptTypeP2tr           = "p2tr"
+	ScriptTypeWitnessUnknown = "witness-unknown"
+)
+
+// Address is a decoded address. Hash is set for base58 addresses
+// and witness version and program for segwit addresses
+type Address struct {
+	Addr           string `json:"addr" yaml:"addr"`
+	Network        string `json:"network" yaml:"network"`
+	ScriptType     string `json:"scriptType" yaml:"scriptType"`
+	WitnessVersion *byte  `json:"witnessVersion,omitempty" yaml:"witnessVersion,omitempty"`
+	Hash           string `json:"hash,omitempty" yaml:"hash,omitempty"`
+	WitnessProgram string `json:"witnessProgram,omitempty" yaml:"witnessProgram,omitempty"`
+	ScriptPubKey   string `json:"scriptPubKey" yaml:"scriptPubKey"`
+}
+
+// ScriptAddr returns address for the output script on the network.
+// Witness programs of version 1 and above are bech32m encoded
+func ScriptAddr(pkScript []byte, network string) (string, error) {
+	params, ok := netParams[network]
+	if !ok {
+		return "", fmt.Errorf("invalid or unsupported network: %s", network)
+	}
+
+	// witness program is a version opcode followed by a single push
+	// of 2 to 40 bytes per BIP-141
+	if n := len(pkScript); n >= 4 && n <= 42 &&
+		pkScript[0] >= txscript.OP_1 && pkScript[0] <= txscript.OP_16 &&
+		int(pkScript[1]) == n-2 {
+		version := pkScript[0] - txscript.OP_1 + 1
+		return encodeSegWitAddress(params.Bech32HRPSegwit, version, pkScript[2:])
+	}
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract address from script: %w", err)
+	}
+
+	if len(addrs) != 1 {
+		return "", fmt.Errorf("script does not correspond to a single address")
+	}
+
+	return addrs[0].EncodeAddress(), nil
+}
+
+// AddrScript returns output script for the address along with the
+// network it belongs to. Segwit addresses of all witness versions
+// are supported
+func AddrScript(addr string) ([]byte, string, error) {
+	address, err := DecodeAddress(addr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pkScript, err := hex.DecodeString(address.ScriptPubKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode script pub key: %w", err)
+	}
+
+	return pkScript, address.Network, nil
+}
+
+// IsSegWitAddr returns true if the address has the segwit
+// human readable part of a supported network
+func IsSegWitAddr(addr string) bool {
+	for _, network := range networkTypes {
+		if strings.HasPrefix(strings.ToLower(addr), netParams[network].Bech32HRPSegwit+"1") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DecodeAddress decodes base58 p2pkh and p2sh addresses and bech32 or
+// bech32m encoded segwit addresses into their components. Testnet, signet
+// and regtest share base58 version bytes and testnet is reported for them
+func DecodeAddress(addr string) (*Address, error) {
+	for _, network := range networkTypes {
+		if strings.HasPrefix(strings.ToLower(addr), netParams[network].Bech32HRPSegwit+"1") {
+			return decodeSegWitAddr(addr, network)
+		}
+	}
+
+	return decodeBase58Addr(addr)
+}
+
+// decodeSegWitAddr decodes segwit address of the network
+func decodeSegWitAddr(addr, network string) (*Address, error) {
+	_, version, program, err := decodeSegWitAddress(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode segwit address: %w", err)
+	}
+
+	versionOp := byte(txscript.OP_0)
+	if version > 0 {
+		versionOp = txscript.OP_1 + version - 1
+	}
+
+	scriptType := ScriptTypeWitnessUnknown
+	switch {
+	case version == 0 && len(program) == 20:
+		scriptType = ScriptTypeP2wpkh
+	case version == 0 && len(program) == 32:
+		scriptType = ScriptTypeP2wsh
+	case version == 1 && len(program) == 32:
+		scriptType = ScriptTypeP2tr
+	}
+
+	return &Address{
+		Addr:           strings.ToLower(addr),
+		Network:        network,
+		ScriptType:     scriptType,
+		WitnessVersion: &version,
+		WitnessProgram: hex.EncodeToString(program),
+		ScriptPubKey:   hex.EncodeToString(append([]byte{versionOp, byte(len(program))}, program...)),
+	}, nil
+}
+
+// decodeBase58Addr decodes base58 check encoded p2pkh or p2sh address
+func decodeBase58Addr(addr string) (*Address, error) {
+	for i, c := range addr {
+		if _, ok := base58CharMap[c]; !ok {
+			return nil, fmt.Errorf("invalid base58 character %q at position %d", c, i)
+		}
+	}
+
+	b := base58.Decode(addr)
+	if len(b) != 25 {
+		return nil, fmt.Errorf("invalid base58 address length %d bytes, expected 25", len(b))
+	}
+
+	payload, checksum := b[:21], b[21:]
+	if expected := chainhash.DoubleHashB(payload)[:4]; !bytes.Equal(checksum, expected) {
+		return nil, fmt.Errorf("invalid base58 checksum %x, expected %x", checksum, expected)
+	}
+
+	version, hash := payload[0], payload[1:]
+	for _, network := range networkTypes {
+		params := netParams[network]
+
+		var a btcutil.Address
+		var scriptType string
+		var err error
+		switch version {
+		case params.PubKeyHashAddrID:
+			a, err = btcutil.NewAddressPubKeyHash(hash, params)
+			scriptType = ScriptTypeP2pkh
+		case params.ScriptHashAddrID:
+			a, err = btcutil.NewAddressScriptHashFromHash(hash, params)
+			scriptType = ScriptTypeP2sh
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate address: %w", err)
+		}
+
+		pkScript, err := txscript.PayToAddrScript(a)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate pay to addr script: %w", err)
+		}
+
+		return &Address{
+			Addr:         addr,
+			Network:      network,
+			ScriptType:   scriptType,
+			Hash:         hex.EncodeToString(hash),
+			ScriptPubKey: hex.EncodeToString(pkScript),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("invalid or unsupported address version byte 0x%02x", version)
+}
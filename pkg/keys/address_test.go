@@ -0,0 +1,138 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+)
+
+// segwit test vectors from
+// https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki#test-vectors-for-v0-v16-native-segregated-witness-addresses
+func TestDecodeAddress(t *testing.T) {
+	tests := []struct {
+		addr         string
+		network      string
+		scriptType   string
+		scriptPubKey string
+	}{
+		{
+			addr:         "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4",
+			network:      NetworkTypeMainnet,
+			scriptType:   ScriptTypeP2wpkh,
+			scriptPubKey: "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+		},
+		{
+			addr:         "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7",
+			network:      NetworkTypeTestnet,
+			scriptType:   ScriptTypeP2wsh,
+			scriptPubKey: "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262",
+		},
+		{
+			addr:         "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y",
+			network:      NetworkTypeMainnet,
+			scriptType:   ScriptTypeWitnessUnknown,
+			scriptPubKey: "5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6",
+		},
+		{
+			addr:         "BC1SW50QGDZ25J",
+			network:      NetworkTypeMainnet,
+			scriptType:   ScriptTypeWitnessUnknown,
+			scriptPubKey: "6002751e",
+		},
+		{
+			addr:         "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs",
+			network:      NetworkTypeMainnet,
+			scriptType:   ScriptTypeWitnessUnknown,
+			scriptPubKey: "5210751e76e8199196d454941c45d1b3a323",
+		},
+		{
+			addr:         "tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c",
+			network:      NetworkTypeTestnet,
+			scriptType:   ScriptTypeP2tr,
+			scriptPubKey: "5120000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433",
+		},
+		{
+			addr:         "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+			network:      NetworkTypeMainnet,
+			scriptType:   ScriptTypeP2tr,
+			scriptPubKey: "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		},
+		{
+			addr:         "bcrt1q6rz28mcfaxtmd6v789l9rrlrusdprr9pz3cppk",
+			network:      NetworkTypeRegtest,
+			scriptType:   ScriptTypeP2wpkh,
+			scriptPubKey: "0014d0c4a3ef09e997b6e99e397e518fe3e41a118ca1",
+		},
+		{
+			addr:         "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu",
+			network:      NetworkTypeMainnet,
+			scriptType:   ScriptTypeP2pkh,
+			scriptPubKey: "76a91476a04053bda0a88bda5177b86a15c3b29f55987388ac",
+		},
+		{
+			addr:         "3CWFddi6m4ndiGyKqzYvsFYagqDLPVMTzC",
+			network:      NetworkTypeMainnet,
+			scriptType:   ScriptTypeP2sh,
+			scriptPubKey: "a91476a04053bda0a88bda5177b86a15c3b29f55987387",
+		},
+		{
+			addr:         "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn",
+			network:      NetworkTypeTestnet,
+			scriptType:   ScriptTypeP2pkh,
+			scriptPubKey: "76a914243f1394f44554f4ce3fd68649c19adc483ce92488ac",
+		},
+	}
+
+	for _, test := range tests {
+		address, err := DecodeAddress(test.addr)
+		if err != nil {
+			t.Fatal(test.addr, err)
+		}
+
+		if address.Network != test.network || address.ScriptType != test.scriptType || address.ScriptPubKey != test.scriptPubKey {
+			t.Fatal("expected", test.network, test.scriptType, test.scriptPubKey, ", got", address.Network, address.ScriptType, address.ScriptPubKey)
+		}
+
+		if (address.WitnessVersion == nil) == (len(address.Hash) == 0) {
+			t.Fatal("expected either witness version or hash for", test.addr)
+		}
+
+		// decoded script maps back to the normalized address
+		addr, err := ScriptAddr(mustDecodeHex(address.ScriptPubKey), address.Network)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if addr != address.Addr {
+			t.Fatal("expected", address.Addr, ", got", addr)
+		}
+	}
+}
+
+func TestDecodeAddress_Invalid(t *testing.T) {
+	tests := []struct {
+		addr string
+		err  string
+	}{
+		{addr: "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", err: "has bech32 checksum, expected bech32m"},
+		{addr: "BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL", err: "has bech32 checksum, expected bech32m"},
+		{addr: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", err: "has bech32m checksum, expected bech32"},
+		{addr: "tb1q0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq24jc47", err: "has bech32m checksum, expected bech32"},
+		{addr: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", err: "invalid bech32 checksum v8f3t5, expected v8f3t4"},
+		{addr: "BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P", err: "invalid witness program length 16 for witness version 0"},
+		{addr: "bc1zw508d6qejxtdg4y5r3zarvaryvqyzf3du", err: "bech32m"},
+		{addr: "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggv", err: "invalid base58 checksum 64d9b9f7, expected 64d9b9f6"},
+		{addr: "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVgg0", err: "invalid base58 character '0' at position 33"},
+		{addr: "1BpEi6DfDAUFd7GtittLSdBeYJvcoa", err: "invalid base58 address length"},
+	}
+
+	for _, test := range tests {
+		_, err := DecodeAddress(test.addr)
+		if err == nil {
+			t.Fatal("expected error for", test.addr)
+		}
+
+		if !strings.Contains(err.Error(), test.err) {
+			t.Fatal("expected", test.err, ", got", err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package keys
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/bech32"
+)
+
+// bech32 pkg only supports original bech32 checksum, hence bech32m
+// checksum for segwit v1+ addresses is computed here per
+// https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+	bech32Chars  = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+var bech32Gen = []int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) int {
+	chk := 1
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ int(v)
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= bech32Gen[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+func bech32HrpExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+
+	return out
+}
+
+// encodeSegWitAddress encodes witness program as a segwit address using
+// bech32 checksum for witness version 0 and bech32m for higher versions
+func encodeSegWitAddress(hrp string, version byte, program []byte) (string, error) {
+	if version > 16 {
+		return "", fmt.Errorf("invalid witness version %d", version)
+	}
+
+	if len(program) < 2 || len(program) > 40 {
+		return "", fmt.Errorf("invalid witness program length %d", len(program))
+	}
+
+	converted, err := bech32.ConvertBits(program, 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert witness program bits: %w", err)
+	}
+
+	data := append([]byte{version}, converted...)
+
+	constant := bech32Const
+	if version > 0 {
+		constant = bech32mConst
+	}
+
+	values := append(bech32HrpExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ constant
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		sb.WriteByte(bech32Chars[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Chars[(polymod>>uint(5*(5-i)))&31])
+	}
+
+	return sb.String(), nil
+}
+
+// decodeSegWitAddress decodes a segwit address into its human readable
+// part, witness version and program checking bech32 checksum for witness
+// version 0 and bech32m checksum for higher versions
+func decodeSegWitAddress(addr string) (string, byte, []byte, error) {
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		return "", 0, nil, fmt.Errorf("mixed case in segwit address")
+	}
+	addr = strings.ToLower(addr)
+
+	pos := strings.LastIndexByte(addr, '1')
+	if pos < 1 || pos+7 > len(addr) {
+		return "", 0, nil, fmt.Errorf("invalid separator position in segwit address")
+	}
+
+	hrp := addr[:pos]
+	data := make([]byte, 0, len(addr)-pos-1)
+	for i := pos + 1; i < len(addr); i++ {
+		d := strings.IndexByte(bech32Chars, addr[i])
+		if d < 0 {
+			return "", 0, nil, fmt.Errorf("invalid character %q in segwit address", addr[i])
+		}
+		data = append(data, byte(d))
+	}
+
+	if len(data) < 7 {
+		return "", 0, nil, fmt.Errorf("segwit address data too short")
+	}
+
+	version := data[0]
+	constant, encoding, other := bech32Const, "bech32", "bech32m"
+	if version > 0 {
+		constant, encoding, other = bech32mConst, "bech32m", "bech32"
+	}
+
+	values := append(bech32HrpExpand(hrp), data...)
+	if polymod := bech32Polymod(values); polymod != constant {
+		if polymod == constant^bech32Const^bech32mConst {
+			return "", 0, nil, fmt.Errorf("witness version %d address has %s checksum, expected %s", version, other, encoding)
+		}
+
+		// expected checksum is computed over data with zeroed checksum
+		values = append(values[:len(values)-6], 0, 0, 0, 0, 0, 0)
+		expected := bech32Polymod(values) ^ constant
+		checksum := make([]byte, 6)
+		for i := range checksum {
+			checksum[i] = bech32Chars[(expected>>uint(5*(5-i)))&31]
+		}
+
+		return "", 0, nil, fmt.Errorf("invalid %s checksum %s, expected %s", encoding, addr[len(addr)-6:], checksum)
+	}
+
+	if version > 16 {
+		return "", 0, nil, fmt.Errorf("invalid witness version %d", version)
+	}
+
+	program, err := bech32.ConvertBits(data[1:len(data)-6], 5, 8, false)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to convert witness program bits: %w", err)
+	}
+
+	if len(program) < 2 || len(program) > 40 {
+		return "", 0, nil, fmt.Errorf("invalid witness program length %d", len(program))
+	}
+
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return "", 0, nil, fmt.Errorf("invalid witness program length %d for witness version 0", len(program))
+	}
+
+	return hrp, version, program, nil
+}
@@ -0,0 +1,459 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// message signature formats, where legacy format refers to BIP-137
+// signatures and simple and full formats are defined in
+// https://github.com/bitcoin/bips/blob/master/bip-0322.mediawiki
+const (
+	MessageFormatLegacy = "legacy"
+	MessageFormatSimple = "simple"
+	MessageFormatFull   = "full"
+)
+
+// taproot sighash types allowed for BIP-322 key path signatures
+const (
+	sigHashDefault = 0x00
+	sigHashAll     = 0x01
+)
+
+// bip322MessageHash returns tagged hash of the message
+func bip322MessageHash(message string) []byte {
+	return taggedHash("BIP0322-signed-message", []byte(message))
+}
+
+// bip322ToSpend returns the virtual transaction whose only output
+// is locked by the message challenge, i.e., the address script
+func bip322ToSpend(message string, pkScript []byte) *wire.MsgTx {
+	sigScript := append([]byte{txscript.OP_0, txscript.OP_DATA_32}, bip322MessageHash(message)...)
+
+	txIn := wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), sigScript, nil)
+	txIn.Sequence = 0
+
+	tx := wire.NewMsgTx(0)
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, pkScript))
+
+	return tx
+}
+
+// bip322ToSign returns the unsigned virtual transaction spending
+// the output of the to_spend transaction
+func bip322ToSign(toSpend *wire.MsgTx) *wire.MsgTx {
+	hash := toSpend.TxHash()
+
+	txIn := wire.NewTxIn(wire.NewOutPoint(&hash, 0), nil, nil)
+	txIn.Sequence = 0
+
+	tx := wire.NewMsgTx(0)
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+
+	return tx
+}
+
+// SignMessageBip322 signs the message for the address of the key per BIP-322
+// using simple or full signature format. Key must contain a private key and
+// the address can be p2pkh, p2wpkh-p2sh, p2wpkh or p2tr. Simple format for
+// p2pkh addresses produces a legacy signature as required by BIP-322
+func SignMessageBip322(key *Key, message, format string) (*SignedMessage, error) {
+	format = strings.ToLower(format)
+	switch format {
+	case MessageFormatSimple, MessageFormatFull:
+	default:
+		return nil, fmt.Errorf("invalid or unsupported message format: %s. allowed formats are %v", format,
+			[]string{MessageFormatSimple, MessageFormatFull},
+		)
+	}
+
+	if len(key.PrvKeyWif) == 0 {
+		return nil, fmt.Errorf("private key is required for message signing")
+	}
+
+	if len(key.Addr) == 0 {
+		return nil, fmt.Errorf("address is required for message signing")
+	}
+
+	wif, err := btcutil.DecodeWIF(key.PrvKeyWif)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode wif: %w", err)
+	}
+
+	pkScript, _, err := AddrScript(key.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey := wif.SerializePubKey()
+	pubKeyHash := btcutil.Hash160(pubKey)
+	toSign := bip322ToSign(bip322ToSpend(message, pkScript))
+	txIn := toSign.TxIn[0]
+
+	var addrType string
+	switch {
+	case txscript.GetScriptClass(pkScript) == txscript.PubKeyHashTy:
+		addrType = AddrTypeP2pkhOrP2sh
+		if !bytes.Equal(pkScript[3:23], pubKeyHash) {
+			return nil, fmt.Errorf("private key does not match p2pkh address %s", key.Addr)
+		}
+
+		if format == MessageFormatSimple {
+			return SignMessage(key.PrvKeyWif, addrType, message)
+		}
+
+		sig, err := txscript.RawTxInSignature(toSign, 0, pkScript, txscript.SigHashAll, wif.PrivKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign p2pkh input: %w", err)
+		}
+
+		if txIn.SignatureScript, err = txscript.NewScriptBuilder().AddData(sig).AddData(pubKey).Script(); err != nil {
+			return nil, fmt.Errorf("failed to build signature script: %w", err)
+		}
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		addrType = AddrTypeP2wpkh
+		if !wif.CompressPubKey || !bytes.Equal(pkScript[2:], pubKeyHash) {
+			return nil, fmt.Errorf("private key does not match p2wpkh address %s", key.Addr)
+		}
+
+		sig, err := txscript.RawTxInWitnessSignature(toSign, txscript.NewTxSigHashes(toSign), 0, 0, pkScript, txscript.SigHashAll, wif.PrivKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign p2wpkh input: %w", err)
+		}
+
+		txIn.Witness = wire.TxWitness{sig, pubKey}
+	case txscript.IsPayToScriptHash(pkScript):
+		addrType = AddrTypeP2wpkhP2sh
+		redeemScript := append([]byte{txscript.OP_0, txscript.OP_DATA_20}, pubKeyHash...)
+		if !wif.CompressPubKey || !bytes.Equal(pkScript[2:22], btcutil.Hash160(redeemScript)) {
+			return nil, fmt.Errorf("private key does not match p2wpkh-p2sh address %s", key.Addr)
+		}
+
+		sig, err := txscript.RawTxInWitnessSignature(toSign, txscript.NewTxSigHashes(toSign), 0, 0, redeemScript, txscript.SigHashAll, wif.PrivKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign p2wpkh-p2sh input: %w", err)
+		}
+
+		if txIn.SignatureScript, err = txscript.NewScriptBuilder().AddData(redeemScript).Script(); err != nil {
+			return nil, fmt.Errorf("failed to build signature script: %w", err)
+		}
+		txIn.Witness = wire.TxWitness{sig, pubKey}
+	case isPayToTaproot(pkScript):
+		addrType = AddrTypeP2tr
+		_, outputKey, err := taprootOutputKey(pubKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if !bytes.Equal(pkScript[2:], outputKey) {
+			return nil, fmt.Errorf("private key does not match p2tr address %s", key.Addr)
+		}
+
+		tweaked, err := taprootTweakPrivKey(wif.PrivKey)
+		if err != nil {
+			return nil, err
+		}
+
+		sigHash, err := taprootSigHash(toSign, pkScript, sigHashDefault)
+		if err != nil {
+			return nil, err
+		}
+
+		aux := make([]byte, 32)
+		if _, err := rand.Read(aux); err != nil {
+			return nil, fmt.Errorf("failed to read random bytes: %w", err)
+		}
+
+		sig, err := schnorrSign(tweaked, sigHash, aux)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign p2tr input: %w", err)
+		}
+
+		txIn.Witness = wire.TxWitness{sig}
+	default:
+		return nil, fmt.Errorf("unsupported address %s, only single key p2pkh, p2wpkh-p2sh, p2wpkh and p2tr addresses can be signed for", key.Addr)
+	}
+
+	var b bytes.Buffer
+	switch format {
+	case MessageFormatSimple:
+		if err := writeWitness(&b, txIn.Witness); err != nil {
+			return nil, err
+		}
+	case MessageFormatFull:
+		if err := toSign.Serialize(&b); err != nil {
+			return nil, fmt.Errorf("failed to serialize to_sign transaction: %w", err)
+		}
+	}
+
+	return &SignedMessage{
+		Addr:      key.Addr,
+		AddrType:  addrType,
+		PubKeyHex: hex.EncodeToString(pubKey),
+		Message:   message,
+		Signature: base64.StdEncoding.EncodeToString(b.Bytes()),
+		Format:    format,
+	}, nil
+}
+
+// VerifyMessageBip322 verifies BIP-322 signature in simple or full format
+// against the address. Witness v0 and legacy scripts are executed using
+// the script engine while p2tr signatures are verified for key path
+// spending. Simple signatures for p2pkh addresses are verified as legacy
+// signatures and nested segwit scripts are inferred from the witness
+func VerifyMessageBip322(addr, signature, message, format string) (*SignedMessage, error) {
+	format = strings.ToLower(format)
+
+	pkScript, _, err := AddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 signature: %w", err)
+	}
+
+	toSpend := bip322ToSpend(message, pkScript)
+
+	var toSign *wire.MsgTx
+	switch format {
+	case MessageFormatSimple:
+		if txscript.GetScriptClass(pkScript) == txscript.PubKeyHashTy {
+			return VerifyMessage(addr, signature, message)
+		}
+
+		witness, err := readWitness(bytes.NewReader(sig))
+		if err != nil {
+			return nil, err
+		}
+
+		toSign = bip322ToSign(toSpend)
+		toSign.TxIn[0].Witness = witness
+
+		// nested segwit requires the redeem script, which is
+		// inferred from the public key at the end of the witness
+		if txscript.IsPayToScriptHash(pkScript) && len(witness) > 0 {
+			pubKey := witness[len(witness)-1]
+			redeemScript := append([]byte{txscript.OP_0, txscript.OP_DATA_20}, btcutil.Hash160(pubKey)...)
+			if toSign.TxIn[0].SignatureScript, err = txscript.NewScriptBuilder().AddData(redeemScript).Script(); err != nil {
+				return nil, fmt.Errorf("failed to build signature script: %w", err)
+			}
+		}
+	case MessageFormatFull:
+		toSign = wire.NewMsgTx(0)
+		if err := toSign.Deserialize(bytes.NewReader(sig)); err != nil {
+			return nil, fmt.Errorf("failed to deserialize to_sign transaction: %w", err)
+		}
+
+		if err := checkBip322ToSign(toSign, toSpend); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid or unsupported message format: %s. allowed formats are %v", format,
+			[]string{MessageFormatSimple, MessageFormatFull},
+		)
+	}
+
+	txIn := toSign.TxIn[0]
+	verified := &SignedMessage{
+		Addr:      addr,
+		Message:   message,
+		Signature: signature,
+		Format:    format,
+	}
+
+	if isPayToTaproot(pkScript) {
+		if err := verifyTaprootKeyPath(toSign, pkScript); err != nil {
+			return nil, fmt.Errorf("signature verification failed: %w", err)
+		}
+
+		// internal key cannot be recovered from the output key
+		verified.AddrType = AddrTypeP2tr
+		return verified, nil
+	}
+
+	engine, err := txscript.NewEngine(pkScript, toSign, 0, txscript.StandardVerifyFlags, nil,
+		txscript.NewTxSigHashes(toSign), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create script engine: %w", err)
+	}
+
+	if err := engine.Execute(); err != nil {
+		return nil, fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	switch {
+	case txscript.GetScriptClass(pkScript) == txscript.PubKeyHashTy:
+		verified.AddrType = AddrTypeP2pkhOrP2sh
+		if pushes, err := txscript.PushedData(txIn.SignatureScript); err == nil && len(pushes) == 2 {
+			verified.PubKeyHex = hex.EncodeToString(pushes[1])
+		}
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		verified.AddrType = AddrTypeP2wpkh
+		verified.PubKeyHex = hex.EncodeToString(txIn.Witness[len(txIn.Witness)-1])
+	case txscript.IsPayToWitnessScriptHash(pkScript):
+		verified.AddrType = AddrTypeP2wsh
+	case txscript.IsPayToScriptHash(pkScript):
+		verified.AddrType = AddrTypeP2sh
+		if pushes, err := txscript.PushedData(txIn.SignatureScript); err == nil && len(pushes) == 1 &&
+			txscript.IsPayToWitnessPubKeyHash(pushes[0]) {
+			verified.AddrType = AddrTypeP2wpkhP2sh
+			verified.PubKeyHex = hex.EncodeToString(txIn.Witness[len(txIn.Witness)-1])
+		}
+	}
+
+	return verified, nil
+}
+
+// checkBip322ToSign checks that the to_sign transaction provided
+// in full format spends the to_spend transaction output
+func checkBip322ToSign(toSign, toSpend *wire.MsgTx) error {
+	if len(toSign.TxIn) != 1 {
+		return fmt.Errorf("to_sign transaction must have exactly one input")
+	}
+
+	if len(toSign.TxOut) != 1 {
+		return fmt.Errorf("to_sign transaction must have exactly one output")
+	}
+
+	hash := toSpend.TxHash()
+	if toSign.TxIn[0].PreviousOutPoint != *wire.NewOutPoint(&hash, 0) {
+		return fmt.Errorf("to_sign transaction does not spend to_spend transaction %s", hash)
+	}
+
+	if out := toSign.TxOut[0]; out.Value != 0 || !bytes.Equal(out.PkScript, []byte{txscript.OP_RETURN}) {
+		return fmt.Errorf("to_sign transaction output must be zero value OP_RETURN")
+	}
+
+	return nil
+}
+
+// verifyTaprootKeyPath verifies BIP-340 signature in the witness of the
+// only input of the transaction spending the p2tr output script
+func verifyTaprootKeyPath(tx *wire.MsgTx, pkScript []byte) error {
+	witness := tx.TxIn[0].Witness
+	if len(witness) != 1 {
+		return fmt.Errorf("expected single witness item for p2tr key path spend, got %d", len(witness))
+	}
+
+	sig := witness[0]
+	hashType := byte(sigHashDefault)
+	switch len(sig) {
+	case 64:
+	case 65:
+		hashType = sig[64]
+		if hashType != sigHashAll {
+			return fmt.Errorf("unsupported taproot sighash type %#x", hashType)
+		}
+		sig = sig[:64]
+	default:
+		return fmt.Errorf("invalid taproot signature length %d", len(sig))
+	}
+
+	sigHash, err := taprootSigHash(tx, pkScript, hashType)
+	if err != nil {
+		return err
+	}
+
+	return schnorrVerify(pkScript[2:], sigHash, sig)
+}
+
+// taprootSigHash returns BIP-341 signature hash for key path spending
+// the only input of the transaction with SIGHASH_DEFAULT or SIGHASH_ALL,
+// where the output being spent has zero value and the input has no annex
+// https://github.com/bitcoin/bips/blob/master/bip-0341.mediawiki#common-signature-message
+func taprootSigHash(tx *wire.MsgTx, pkScript []byte, hashType byte) ([]byte, error) {
+	if len(tx.TxIn) != 1 {
+		return nil, fmt.Errorf("taproot signature hash requires a single input")
+	}
+
+	var prevOuts, amounts, scripts, sequences, outputs bytes.Buffer
+	for _, txIn := range tx.TxIn {
+		prevOuts.Write(txIn.PreviousOutPoint.Hash[:])
+		_ = binary.Write(&prevOuts, binary.LittleEndian, txIn.PreviousOutPoint.Index)
+		_ = binary.Write(&amounts, binary.LittleEndian, int64(0))
+		if err := wire.WriteVarBytes(&scripts, 0, pkScript); err != nil {
+			return nil, fmt.Errorf("failed to serialize script: %w", err)
+		}
+		_ = binary.Write(&sequences, binary.LittleEndian, txIn.Sequence)
+	}
+
+	for _, txOut := range tx.TxOut {
+		if err := wire.WriteTxOut(&outputs, 0, 0, txOut); err != nil {
+			return nil, fmt.Errorf("failed to serialize output: %w", err)
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteByte(0) // sighash epoch
+	b.WriteByte(hashType)
+	_ = binary.Write(&b, binary.LittleEndian, tx.Version)
+	_ = binary.Write(&b, binary.LittleEndian, tx.LockTime)
+	for _, buf := range []bytes.Buffer{prevOuts, amounts, scripts, sequences, outputs} {
+		h := sha256.Sum256(buf.Bytes())
+		b.Write(h[:])
+	}
+	b.WriteByte(0) // spend type, key path without annex
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0))
+
+	return taggedHash("TapSighash", b.Bytes()), nil
+}
+
+// isPayToTaproot returns true if the script is a segwit v1 output script
+func isPayToTaproot(pkScript []byte) bool {
+	return len(pkScript) == 34 && pkScript[0] == txscript.OP_1 && pkScript[1] == txscript.OP_DATA_32
+}
+
+// writeWitness writes consensus encoded witness stack
+func writeWitness(w *bytes.Buffer, witness wire.TxWitness) error {
+	if err := wire.WriteVarInt(w, 0, uint64(len(witness))); err != nil {
+		return fmt.Errorf("failed to serialize witness: %w", err)
+	}
+
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(w, 0, item); err != nil {
+			return fmt.Errorf("failed to serialize witness: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readWitness reads consensus encoded witness stack
+func readWitness(r *bytes.Reader) (wire.TxWitness, error) {
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read witness item count: %w", err)
+	}
+
+	if count > uint64(r.Len()) {
+		return nil, fmt.Errorf("invalid witness item count %d", count)
+	}
+
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		if witness[i], err = wire.ReadVarBytes(r, 0, txscript.MaxScriptSize, "witness item"); err != nil {
+			return nil, fmt.Errorf("failed to read witness item: %w", err)
+		}
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("unexpected %d bytes after witness", r.Len())
+	}
+
+	return witness, nil
+}
@@ -0,0 +1,221 @@
+package keys
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil"
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+// BIP-322 test vectors from
+// https://github.com/bitcoin/bips/blob/master/bip-0322.mediawiki#test-vectors
+const (
+	bip322Wif        = "L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k"
+	bip322P2wpkhAddr = "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+	bip322P2trAddr   = "bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3"
+)
+
+func TestBip322_MessageHash(t *testing.T) {
+	tests := []struct {
+		message string
+		hash    string
+	}{
+		{message: "", hash: "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1"},
+		{message: "Hello World", hash: "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a"},
+	}
+
+	for _, test := range tests {
+		if hash := hex.EncodeToString(bip322MessageHash(test.message)); hash != test.hash {
+			t.Fatal("expected", test.hash, ", got", hash)
+		}
+	}
+}
+
+func TestBip322_Transactions(t *testing.T) {
+	pkScript, _, err := AddrScript(bip322P2wpkhAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		toSpend string
+		toSign  string
+	}{
+		{
+			message: "",
+			toSpend: "c5680aa69bb8d860bf82d4e9cd3504b55dde018de765a91bb566283c545a99a7",
+			toSign:  "1e9654e951a5ba44c8604c4de6c67fd78a27e81dcadcfe1edf638ba3aaebaed6",
+		},
+		{
+			message: "Hello World",
+			toSpend: "b79d196740ad5217771c1098fc4a4b51e0535c32236c71f1ea4d61a2d603352b",
+			toSign:  "88737ae86f2077145f93cc4b153ae9a1cb8d56afa511988c149c5c8c9d93bddf",
+		},
+	}
+
+	for _, test := range tests {
+		toSpend := bip322ToSpend(test.message, pkScript)
+		if txid := toSpend.TxHash().String(); txid != test.toSpend {
+			t.Fatal("expected to_spend", test.toSpend, ", got", txid)
+		}
+
+		toSign := bip322ToSign(toSpend)
+		if txid := toSign.TxHash().String(); txid != test.toSign {
+			t.Fatal("expected to_sign", test.toSign, ", got", txid)
+		}
+	}
+}
+
+func TestVerifyMessageBip322_Vectors(t *testing.T) {
+	tests := []struct {
+		addr      string
+		message   string
+		signature string
+	}{
+		{
+			addr:      bip322P2wpkhAddr,
+			message:   "",
+			signature: "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+		},
+		{
+			addr:      bip322P2wpkhAddr,
+			message:   "Hello World",
+			signature: "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+		},
+		{
+			addr:      bip322P2wpkhAddr,
+			message:   "Hello World",
+			signature: "AkgwRQIhAOzyynlqt93lOKJr+wmmxIens//zPzl9tqIOua93wO6MAiBi5n5EyAcPScOjf1lAqIUIQtr3zKNeavYabHyR8eGhowEhAsfxIAMZZEKUPYWI4BruhAQjzFT8FSFSajuFwrDL1Yhy",
+		},
+		{
+			addr:      bip322P2trAddr,
+			message:   "Hello World",
+			signature: "AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ==",
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := VerifyMessageBip322(test.addr, test.signature, test.message, MessageFormatSimple); err != nil {
+			t.Fatal(err, "for", test.addr, test.message)
+		}
+
+		if _, err := VerifyMessageBip322(test.addr, test.signature, test.message+"!", MessageFormatSimple); err == nil {
+			t.Fatal("expected verification to fail for modified message for", test.addr)
+		}
+	}
+}
+
+func TestSignMessageBip322_Vectors(t *testing.T) {
+	wif, err := btcutil.DecodeWIF(bip322Wif)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubKeyHex := hex.EncodeToString(wif.SerializePubKey())
+	for _, test := range []struct {
+		addrType string
+		addr     string
+	}{
+		{addrType: AddrTypeP2wpkh, addr: bip322P2wpkhAddr},
+		{addrType: AddrTypeP2tr, addr: bip322P2trAddr},
+	} {
+		addr, err := SingleKeyAddr(pubKeyHex, test.addrType, NetworkTypeMainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if addr != test.addr {
+			t.Fatal("expected", test.addr, ", got", addr)
+		}
+	}
+
+	// ecdsa signatures are deterministic per RFC-6979 and match
+	// the second signature listed for the message in BIP-322
+	key := &Key{PrvKeyWif: bip322Wif, Addr: bip322P2wpkhAddr}
+	signed, err := SignMessageBip322(key, "Hello World", MessageFormatSimple)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "AkgwRQIhAOzyynlqt93lOKJr+wmmxIens//zPzl9tqIOua93wO6MAiBi5n5EyAcPScOjf1lAqIUIQtr3zKNeavYabHyR8eGhowEhAsfxIAMZZEKUPYWI4BruhAQjzFT8FSFSajuFwrDL1Yhy"; signed.Signature != expected {
+		t.Fatal("expected", expected, ", got", signed.Signature)
+	}
+}
+
+func TestSignMessageBip322_AddrTypes(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	for _, addrType := range []string{AddrTypeBip44, AddrTypeBip49, AddrTypeBip84, AddrTypeBip86} {
+		key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "auto", AddrType: addrType})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, format := range []string{MessageFormatSimple, MessageFormatFull} {
+			signed, err := SignMessageBip322(key, "hello world", format)
+			if err != nil {
+				t.Fatal(err, "for", addrType, format)
+			}
+
+			verified, err := VerifyMessageBip322(key.Addr, signed.Signature, "hello world", format)
+			if err != nil {
+				t.Fatal(err, "for", addrType, format)
+			}
+
+			if verified.AddrType != signed.AddrType {
+				t.Fatal("expected", signed.AddrType, ", got", verified.AddrType)
+			}
+
+			if _, err := VerifyMessageBip322(key.Addr, signed.Signature, "hello", format); err == nil {
+				t.Fatal("expected verification to fail for modified message for", addrType, format)
+			}
+		}
+	}
+
+	// keys derived from public keys cannot sign
+	account, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "m/86h/0h/0h", AddrType: AddrTypeBip86})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := Derive(account.XPub, "m/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SignMessageBip322(key, "hello world", MessageFormatSimple); err == nil {
+		t.Fatal("expected error for key without private key")
+	}
+}
+
+func TestSchnorr(t *testing.T) {
+	// test vector 0 from
+	// https://github.com/bitcoin/bips/blob/master/bip-0340/test-vectors.csv
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(),
+		mustDecodeHex("0000000000000000000000000000000000000000000000000000000000000003"))
+	pubKey := "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9"
+	aux := make([]byte, 32)
+	msg := make([]byte, 32)
+	expected := "e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca821525f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0"
+
+	sig, err := schnorrSign(privKey.D, msg, aux)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hex.EncodeToString(sig) != expected {
+		t.Fatal("expected", expected, ", got", hex.EncodeToString(sig))
+	}
+
+	if err := schnorrVerify(mustDecodeHex(pubKey), msg, sig); err != nil {
+		t.Fatal(err)
+	}
+
+	sig[63] ^= 1
+	if err := schnorrVerify(mustDecodeHex(pubKey), msg, sig); err == nil {
+		t.Fatal("expected verification to fail for modified signature")
+	}
+}
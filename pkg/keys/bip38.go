@@ -0,0 +1,452 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// BIP-38 prefixes and flags per
+// https://github.com/bitcoin/bips/blob/master/bip-0038.mediawiki
+const (
+	bip38Version           = 0x01
+	bip38TypeNonEc         = 0x42
+	bip38TypeEc            = 0x43
+	bip38FlagNonEc         = 0xc0
+	bip38FlagCompressed    = 0x20
+	bip38FlagLotSequence   = 0x04
+	bip38IntermediateMagic = 0x2c
+	bip38MaxLot            = 1048575
+	bip38MaxSequence       = 4095
+)
+
+// bip38IntermediatePrefix follows intermediate code magic byte and
+// its last byte is 0x53 when lot and sequence numbers are not used
+var bip38IntermediatePrefix = []byte{0xe9, 0xb3, 0xe1, 0xff, 0x39, 0xe2, 0x51}
+
+// Bip38Key is a BIP-38 encrypted private key generated from
+// an intermediate code along with its address
+type Bip38Key struct {
+	EncryptedKey     string `json:"encryptedKey" yaml:"encryptedKey"`
+	Addr             string `json:"addr" yaml:"addr"`
+	IntermediateCode string `json:"intermediateCode,omitempty" yaml:"intermediateCode,omitempty"`
+	Network          string `json:"network" yaml:"network"`
+}
+
+// EncryptBip38 encrypts wif private key with the passphrase using
+// non-EC-multiplied BIP-38 mode
+func EncryptBip38(wifString, passphrase string) (string, error) {
+	wif, err := btcutil.DecodeWIF(wifString)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode wif: %w", err)
+	}
+
+	params, err := bip38Params(wif)
+	if err != nil {
+		return "", err
+	}
+
+	addrHash, err := bip38AddrHash(wif.SerializePubKey(), params)
+	if err != nil {
+		return "", err
+	}
+
+	derived, err := scrypt.Key(bip38Passphrase(passphrase), addrHash, 16384, 8, 8, 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive key from passphrase: %w", err)
+	}
+
+	block, err := aes.NewCipher(derived[32:])
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	prvKey := wif.PrivKey.Serialize()
+	encrypted := make([]byte, 32)
+	for i := range encrypted {
+		encrypted[i] = prvKey[i] ^ derived[i]
+	}
+	block.Encrypt(encrypted[:16], encrypted[:16])
+	block.Encrypt(encrypted[16:], encrypted[16:])
+
+	flag := byte(bip38FlagNonEc)
+	if wif.CompressPubKey {
+		flag |= bip38FlagCompressed
+	}
+
+	payload := append([]byte{bip38TypeNonEc, flag}, addrHash...)
+	payload = append(payload, encrypted...)
+
+	return base58.CheckEncode(payload, bip38Version), nil
+}
+
+// DecryptBip38 decrypts BIP-38 encrypted private key, EC-multiplied or not,
+// using the passphrase and returns wif private key for the network
+func DecryptBip38(encryptedKey, passphrase, network string) (string, error) {
+	params, ok := netParams[network]
+	if !ok {
+		return "", fmt.Errorf("invalid or unsupported network: %s", network)
+	}
+
+	data, version, err := base58.CheckDecode(encryptedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted key: %w", err)
+	}
+
+	if version != bip38Version || len(data) != 38 {
+		return "", fmt.Errorf("invalid bip38 encrypted key")
+	}
+
+	flag, addrHash := data[1], data[2:6]
+	compressed := flag&bip38FlagCompressed != 0
+
+	var prvKey []byte
+	switch data[0] {
+	case bip38TypeNonEc:
+		if flag&bip38FlagNonEc != bip38FlagNonEc {
+			return "", fmt.Errorf("invalid bip38 flag byte 0x%02x", flag)
+		}
+
+		prvKey, err = bip38DecryptNonEc(data[6:], addrHash, passphrase)
+	case bip38TypeEc:
+		prvKey, err = bip38DecryptEc(data[6:], addrHash, passphrase, flag&bip38FlagLotSequence != 0)
+	default:
+		return "", fmt.Errorf("invalid bip38 encrypted key type 0x%02x", data[0])
+	}
+	if err != nil {
+		return "", err
+	}
+
+	prv, _ := btcec.PrivKeyFromBytes(btcec.S256(), prvKey)
+	wif, err := btcutil.NewWIF(prv, params, compressed)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate wif formatted prv key: %w", err)
+	}
+
+	// address hash acts as checksum of the passphrase
+	expected, err := bip38AddrHash(wif.SerializePubKey(), params)
+	if err != nil {
+		return "", err
+	}
+
+	if !bytes.Equal(expected, addrHash) {
+		return "", fmt.Errorf("invalid passphrase or network")
+	}
+
+	return wif.String(), nil
+}
+
+// NewBip38IntermediateCode generates intermediate code from the passphrase
+// that can be shared with a third party to generate EC-multiplied BIP-38
+// encrypted keys without knowing the passphrase
+func NewBip38IntermediateCode(passphrase string) (string, error) {
+	ownerSalt := make([]byte, 8)
+	if _, err := rand.Read(ownerSalt); err != nil {
+		return "", fmt.Errorf("failed to generate owner salt: %w", err)
+	}
+
+	return bip38IntermediateCode(passphrase, ownerSalt, false)
+}
+
+// NewBip38IntermediateCodeWithLot generates intermediate code similar to
+// NewBip38IntermediateCode, however, encodes lot and sequence numbers
+func NewBip38IntermediateCodeWithLot(passphrase string, lot, sequence uint32) (string, error) {
+	if lot > bip38MaxLot || sequence > bip38MaxSequence {
+		return "", fmt.Errorf("lot needs to be at most %d and sequence at most %d", bip38MaxLot, bip38MaxSequence)
+	}
+
+	ownerSalt := make([]byte, 8)
+	if _, err := rand.Read(ownerSalt[:4]); err != nil {
+		return "", fmt.Errorf("failed to generate owner salt: %w", err)
+	}
+	binary.BigEndian.PutUint32(ownerSalt[4:], lot*4096+sequence)
+
+	return bip38IntermediateCode(passphrase, ownerSalt, true)
+}
+
+// NewBip38Key generates a new private key using the intermediate code and
+// returns it in EC-multiplied BIP-38 encrypted form along with its address
+func NewBip38Key(intermediateCode, network string, compressed bool) (*Bip38Key, error) {
+	seedB := make([]byte, 24)
+	if _, err := rand.Read(seedB); err != nil {
+		return nil, fmt.Errorf("failed to generate seed: %w", err)
+	}
+
+	return newBip38Key(intermediateCode, network, compressed, seedB)
+}
+
+// EncryptKeyWif replaces private wif key of the key with its BIP-38
+// encrypted form and clears all other private key material
+func EncryptKeyWif(key *Key, passphrase string) error {
+	if len(key.PrvKeyWif) == 0 {
+		return fmt.Errorf("key does not have a private wif key to encrypt")
+	}
+
+	encryptedKey, err := EncryptBip38(key.PrvKeyWif, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt private wif key: %w", err)
+	}
+
+	key.PrvKeyWif = encryptedKey
+	key.PrvKeyHex = ""
+	key.XPrv = ""
+	key.Seed = ""
+
+	return nil
+}
+
+// bip38IntermediateCode generates intermediate code using owner salt, whose
+// last four bytes are lot and sequence numbers when lot sequence is used
+func bip38IntermediateCode(passphrase string, ownerEntropy []byte, lotSequence bool) (string, error) {
+	passFactor, err := bip38PassFactor(passphrase, ownerEntropy, lotSequence)
+	if err != nil {
+		return "", err
+	}
+
+	x, y := btcec.S256().ScalarBaseMult(passFactor)
+	passPoint := (&btcec.PublicKey{Curve: btcec.S256(), X: x, Y: y}).SerializeCompressed()
+
+	payload := append([]byte{}, bip38IntermediatePrefix...)
+	if !lotSequence {
+		payload[len(payload)-1] = 0x53
+	}
+	payload = append(payload, ownerEntropy...)
+	payload = append(payload, passPoint...)
+
+	return base58.CheckEncode(payload, bip38IntermediateMagic), nil
+}
+
+// newBip38Key encrypts private key derived from seed b and intermediate code
+func newBip38Key(intermediateCode, network string, compressed bool, seedB []byte) (*Bip38Key, error) {
+	params, ok := netParams[network]
+	if !ok {
+		return nil, fmt.Errorf("invalid or unsupported network: %s", network)
+	}
+
+	data, version, err := base58.CheckDecode(intermediateCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode intermediate code: %w", err)
+	}
+
+	if version != bip38IntermediateMagic || len(data) != 48 ||
+		!bytes.Equal(data[:6], bip38IntermediatePrefix[:6]) || (data[6] != 0x51 && data[6] != 0x53) {
+		return nil, fmt.Errorf("invalid bip38 intermediate code")
+	}
+
+	lotSequence := data[6] == 0x51
+	ownerEntropy, passPoint := data[7:15], data[15:]
+
+	pub, err := btcec.ParsePubKey(passPoint, btcec.S256())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pass point: %w", err)
+	}
+
+	factorB := chainhash.DoubleHashB(seedB)
+	if err := bip38CheckScalar(factorB); err != nil {
+		return nil, err
+	}
+
+	x, y := btcec.S256().ScalarMult(pub.X, pub.Y, factorB)
+	pub = &btcec.PublicKey{Curve: btcec.S256(), X: x, Y: y}
+
+	serializedPubKey := pub.SerializeUncompressed()
+	if compressed {
+		serializedPubKey = pub.SerializeCompressed()
+	}
+
+	addr, err := singleKeyAddr(serializedPubKey, AddrTypeP2pkhOrP2sh, params)
+	if err != nil {
+		return nil, err
+	}
+	addrHash := chainhash.DoubleHashB([]byte(addr))[:4]
+
+	derived, err := scrypt.Key(passPoint, append(append([]byte{}, addrHash...), ownerEntropy...), 1024, 1, 1, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key from pass point: %w", err)
+	}
+
+	block, err := aes.NewCipher(derived[32:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	encryptedPart1 := make([]byte, 16)
+	for i := range encryptedPart1 {
+		encryptedPart1[i] = seedB[i] ^ derived[i]
+	}
+	block.Encrypt(encryptedPart1, encryptedPart1)
+
+	encryptedPart2 := append(append([]byte{}, encryptedPart1[8:]...), seedB[16:]...)
+	for i := range encryptedPart2 {
+		encryptedPart2[i] ^= derived[16+i]
+	}
+	block.Encrypt(encryptedPart2, encryptedPart2)
+
+	flag := byte(0)
+	if compressed {
+		flag |= bip38FlagCompressed
+	}
+	if lotSequence {
+		flag |= bip38FlagLotSequence
+	}
+
+	payload := append([]byte{bip38TypeEc, flag}, addrHash...)
+	payload = append(payload, ownerEntropy...)
+	payload = append(payload, encryptedPart1[:8]...)
+	payload = append(payload, encryptedPart2...)
+
+	return &Bip38Key{
+		EncryptedKey: base58.CheckEncode(payload, bip38Version),
+		Addr:         addr,
+		Network:      network,
+	}, nil
+}
+
+// bip38DecryptNonEc decrypts private key encrypted without EC multiplication
+func bip38DecryptNonEc(encrypted, addrHash []byte, passphrase string) ([]byte, error) {
+	derived, err := scrypt.Key(bip38Passphrase(passphrase), addrHash, 16384, 8, 8, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key from passphrase: %w", err)
+	}
+
+	block, err := aes.NewCipher(derived[32:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	prvKey := make([]byte, 32)
+	block.Decrypt(prvKey[:16], encrypted[:16])
+	block.Decrypt(prvKey[16:], encrypted[16:])
+	for i := range prvKey {
+		prvKey[i] ^= derived[i]
+	}
+
+	if err := bip38CheckScalar(prvKey); err != nil {
+		return nil, fmt.Errorf("invalid passphrase: %w", err)
+	}
+
+	return prvKey, nil
+}
+
+// bip38DecryptEc decrypts seed b of EC-multiplied private key and
+// multiplies its factor with pass factor of the passphrase
+func bip38DecryptEc(data, addrHash []byte, passphrase string, lotSequence bool) ([]byte, error) {
+	ownerEntropy, encryptedPart1, encryptedPart2 := data[:8], data[8:16], data[16:32]
+
+	passFactor, err := bip38PassFactor(passphrase, ownerEntropy, lotSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	x, y := btcec.S256().ScalarBaseMult(passFactor)
+	passPoint := (&btcec.PublicKey{Curve: btcec.S256(), X: x, Y: y}).SerializeCompressed()
+
+	derived, err := scrypt.Key(passPoint, append(append([]byte{}, addrHash...), ownerEntropy...), 1024, 1, 1, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key from pass point: %w", err)
+	}
+
+	block, err := aes.NewCipher(derived[32:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	// second part holds last half of first part and last 8 bytes of seed b
+	part2 := make([]byte, 16)
+	block.Decrypt(part2, encryptedPart2)
+	for i := range part2 {
+		part2[i] ^= derived[16+i]
+	}
+
+	part1 := make([]byte, 16)
+	block.Decrypt(part1, append(append([]byte{}, encryptedPart1...), part2[:8]...))
+	for i := range part1 {
+		part1[i] ^= derived[i]
+	}
+
+	factorB := chainhash.DoubleHashB(append(part1, part2[8:]...))
+
+	n := btcec.S256().N
+	prvKey := new(big.Int).Mul(new(big.Int).SetBytes(passFactor), new(big.Int).SetBytes(factorB))
+	prvKey.Mod(prvKey, n)
+
+	b := make([]byte, 32)
+	prvKey.FillBytes(b)
+
+	if err := bip38CheckScalar(b); err != nil {
+		return nil, fmt.Errorf("invalid passphrase: %w", err)
+	}
+
+	return b, nil
+}
+
+// bip38PassFactor derives pass factor from passphrase and owner entropy
+func bip38PassFactor(passphrase string, ownerEntropy []byte, lotSequence bool) ([]byte, error) {
+	ownerSalt := ownerEntropy
+	if lotSequence {
+		ownerSalt = ownerEntropy[:4]
+	}
+
+	passFactor, err := scrypt.Key(bip38Passphrase(passphrase), ownerSalt, 16384, 8, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key from passphrase: %w", err)
+	}
+
+	if lotSequence {
+		passFactor = chainhash.DoubleHashB(append(passFactor, ownerEntropy...))
+	}
+
+	if err := bip38CheckScalar(passFactor); err != nil {
+		return nil, err
+	}
+
+	return passFactor, nil
+}
+
+// bip38AddrHash returns first four bytes of double sha256 hash of
+// p2pkh address of the public key
+func bip38AddrHash(pubKey []byte, params *chaincfg.Params) ([]byte, error) {
+	addr, err := singleKeyAddr(pubKey, AddrTypeP2pkhOrP2sh, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.DoubleHashB([]byte(addr))[:4], nil
+}
+
+// bip38Params returns btc network params of the wif private key
+func bip38Params(wif *btcutil.WIF) (*chaincfg.Params, error) {
+	for _, network := range networkTypes {
+		if wif.IsForNet(netParams[network]) {
+			return netParams[network], nil
+		}
+	}
+
+	return nil, fmt.Errorf("bip38 encryption is only supported for %s keys", CoinTypeBtc)
+}
+
+// bip38Passphrase returns NFC normalized passphrase
+func bip38Passphrase(passphrase string) []byte {
+	return []byte(norm.NFC.String(passphrase))
+}
+
+// bip38CheckScalar checks that the value is a valid private key
+func bip38CheckScalar(b []byte) error {
+	k := new(big.Int).SetBytes(b)
+	if k.Sign() == 0 || k.Cmp(btcec.S256().N) >= 0 {
+		return fmt.Errorf("invalid private key scalar")
+	}
+
+	return nil
+}
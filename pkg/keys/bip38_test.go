@@ -0,0 +1,160 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// BIP-38 test vectors from
+// https://github.com/bitcoin/bips/blob/master/bip-0038.mediawiki#test-vectors
+func TestBip38_NonEc(t *testing.T) {
+	tests := []struct {
+		passphrase string
+		encrypted  string
+		wif        string
+	}{
+		{
+			passphrase: "TestingOneTwoThree",
+			encrypted:  "6PRVWUbkzzsbcVac2qwfssoUJAN1Xhrg6bNk8J7Nzm5H7kxEbn2Nh2ZoGg",
+			wif:        "5KN7MzqK5wt2TP1fQCYyHBtDrXdJuXbUzm4A9rKAteGu3Qi5CVR",
+		},
+		{
+			passphrase: "Satoshi",
+			encrypted:  "6PRNFFkZc2NZ6dJqFfhRoFNMR9Lnyj7dYGrzdgXXVMXcxoKTePPX1dWByq",
+			wif:        "5HtasZ6ofTHP6HCwTqTkLDuLQisYPah7aUnSKfC7h4hMUVw2gi5",
+		},
+		{
+			passphrase: "TestingOneTwoThree",
+			encrypted:  "6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeo",
+			wif:        "L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP",
+		},
+		{
+			passphrase: "Satoshi",
+			encrypted:  "6PYLtMnXvfG3oJde97zRyLYFZCYizPU5T3LwgdYJz1fRhh16bU7u6PPmY7",
+			wif:        "KwYgW8gcxj1JWJXhPSu4Fqwzfhp5Yfi42mdYmMa4XqK7NJxXUSK7",
+		},
+	}
+
+	for _, test := range tests {
+		encrypted, err := EncryptBip38(test.wif, test.passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if encrypted != test.encrypted {
+			t.Fatal("expected", test.encrypted, ", got", encrypted)
+		}
+
+		wif, err := DecryptBip38(test.encrypted, test.passphrase, NetworkTypeMainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if wif != test.wif {
+			t.Fatal("expected", test.wif, ", got", wif)
+		}
+	}
+
+	if _, err := DecryptBip38(tests[0].encrypted, "wrong", NetworkTypeMainnet); err == nil {
+		t.Fatal("expected error for invalid passphrase")
+	}
+}
+
+func TestBip38_Ec(t *testing.T) {
+	tests := []struct {
+		passphrase       string
+		intermediateCode string
+		encrypted        string
+		wif              string
+		lotSequence      bool
+	}{
+		{
+			passphrase:       "TestingOneTwoThree",
+			intermediateCode: "passphrasepxFy57B9v8HtUsszJYKReoNDV6VHjUSGt8EVJmux9n1J3Ltf1gRxyDGXqnf9qm",
+			encrypted:        "6PfQu77ygVyJLZjfvMLyhLMQbYnu5uguoJJ4kMCLqWwPEdfpwANVS76gTX",
+			wif:              "5K4caxezwjGCGfnoPTZ8tMcJBLB7Jvyjv4xxeacadhq8nLisLR2",
+		},
+		{
+			passphrase:       "Satoshi",
+			intermediateCode: "passphraseoRDGAXTWzbp72eVbtUDdn1rwpgPUGjNZEc6CGBo8i5EC1FPW8wcnLdq4ThKzAS",
+			encrypted:        "6PfLGnQs6VZnrNpmVKfjotbnQuaJK4KZoPFrAjx1JMJUa1Ft8gnf5WxfKd",
+			wif:              "5KJ51SgxWaAYR13zd9ReMhJpwrcX47xTJh2D3fGPG9CM8vkv5sH",
+		},
+		{
+			passphrase:       "MOLON LABE",
+			intermediateCode: "passphraseaB8feaLQDENqCgr4gKZpmf4VoaT6qdjJNJiv7fsKvjqavcJxvuR1hy25aTu5sX",
+			encrypted:        "6PgNBNNzDkKdhkT6uJntUXwwzQV8Rr2tZcbkDcuC9DZRsS6AtHts4Ypo1j",
+			wif:              "5JLdxTtcTHcfYcmJsNVy1v2PMDx432JPoYcBTVVRHpPaxUrdtf8",
+			lotSequence:      true,
+		},
+		{
+			passphrase:       "ΜΟΛΩΝ ΛΑΒΕ",
+			intermediateCode: "passphrased3z9rQJHSyBkNBwTRPkUGNVEVrUAcfAXDyRU1V28ie6hNFbqDwbFBvsTK7yWVK",
+			encrypted:        "6PgGWtx25kUg8QWvwuJAgorN6k9FbE25rv5dMRwu5SKMnfpfVe5mar2ngH",
+			wif:              "5KMKKuUmAkiNbA3DazMQiLfDq47qs8MAEThm4yL8R2PhV1ov33D",
+			lotSequence:      true,
+		},
+	}
+
+	for _, test := range tests {
+		wif, err := DecryptBip38(test.encrypted, test.passphrase, NetworkTypeMainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if wif != test.wif {
+			t.Fatal("expected", test.wif, ", got", wif)
+		}
+
+		// owner entropy is recovered from intermediate code
+		// to regenerate it from the passphrase
+		data, _, err := base58.CheckDecode(test.intermediateCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		intermediateCode, err := bip38IntermediateCode(test.passphrase, data[7:15], test.lotSequence)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if intermediateCode != test.intermediateCode {
+			t.Fatal("expected", test.intermediateCode, ", got", intermediateCode)
+		}
+	}
+}
+
+func TestBip38_EcRoundTrip(t *testing.T) {
+	passphrase := "TestingOneTwoThree"
+
+	intermediateCode, err := NewBip38IntermediateCodeWithLot(passphrase, 263183, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, compressed := range []bool{false, true} {
+		key, err := NewBip38Key(intermediateCode, NetworkTypeTestnet, compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wif, err := DecryptBip38(key.EncryptedKey, passphrase, NetworkTypeTestnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := DecodePrivateWifKey(wif)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoded.Addr != key.Addr || decoded.Uncompressed == compressed {
+			t.Fatal("expected", key.Addr, ", got", decoded.Addr)
+		}
+	}
+
+	if _, err := NewBip38IntermediateCodeWithLot(passphrase, bip38MaxLot+1, 0); err == nil {
+		t.Fatal("expected error for invalid lot")
+	}
+}
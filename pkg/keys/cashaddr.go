@@ -0,0 +1,267 @@
+package keys
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/bech32"
+)
+
+// CashAddr type bits of the version byte per
+// https://github.com/bitcoincashorg/bitcoincash.org/blob/master/spec/cashaddr.md
+const (
+	cashAddrTypeP2pkh = 0
+	cashAddrTypeP2sh  = 1
+)
+
+// cashAddrHashSizes lists hash sizes in bytes indexed
+// by size bits of the version byte
+var cashAddrHashSizes = []int{20, 24, 28, 32, 40, 48, 56, 64}
+
+// https://github.com/bitcoin-cash-node/bitcoin-cash-node/blob/master/src/chainparams.cpp
+var (
+	bitcoinCashMainNetParams = chaincfg.Params{
+		Name:             "bitcoincash-mainnet",
+		PubKeyHashAddrID: 0x00,
+		ScriptHashAddrID: 0x05,
+		PrivateKeyID:     0x80,
+		HDPublicKeyID:    [4]byte{0x04, 0x88, 0xb2, 0x1e},
+		HDPrivateKeyID:   [4]byte{0x04, 0x88, 0xad, 0xe4},
+		HDCoinType:       145,
+	}
+
+	bitcoinCashTestNetParams = chaincfg.Params{
+		Name:             "bitcoincash-testnet",
+		PubKeyHashAddrID: 0x6f,
+		ScriptHashAddrID: 0xc4,
+		PrivateKeyID:     0xef,
+		HDPublicKeyID:    [4]byte{0x04, 0x35, 0x87, 0xcf},
+		HDPrivateKeyID:   [4]byte{0x04, 0x35, 0x83, 0x94},
+		HDCoinType:       1,
+	}
+)
+
+// cashAddrPrefixes maps network params to CashAddr prefix
+var cashAddrPrefixes = map[*chaincfg.Params]string{
+	&bitcoinCashMainNetParams: "bitcoincash",
+	&bitcoinCashTestNetParams: "bchtest",
+}
+
+// bchEncodeAddr returns CashAddr encoded p2pkh address
+// for the serialized public key
+func bchEncodeAddr(pubKey []byte, addrType string, params *chaincfg.Params) (string, error) {
+	if addrType != AddrTypeP2pkhOrP2sh {
+		return "", fmt.Errorf("invalid or unsupported addr type for bch: %s", addrType)
+	}
+
+	prefix, ok := cashAddrPrefixes[params]
+	if !ok {
+		return "", fmt.Errorf("cashaddr prefix not found for %s", params.Name)
+	}
+
+	return encodeCashAddr(prefix, cashAddrTypeP2pkh, btcutil.Hash160(pubKey))
+}
+
+// cashAddrPolymod computes 40 bit checksum of 5 bit values
+func cashAddrPolymod(values []byte) uint64 {
+	c := uint64(1)
+	for _, d := range values {
+		c0 := c >> 35
+		c = (c&0x07ffffffff)<<5 ^ uint64(d)
+		if c0&0x01 != 0 {
+			c ^= 0x98f2bc8e61
+		}
+		if c0&0x02 != 0 {
+			c ^= 0x79b76d99e2
+		}
+		if c0&0x04 != 0 {
+			c ^= 0xf33e5fb3c4
+		}
+		if c0&0x08 != 0 {
+			c ^= 0xae2eabe2a8
+		}
+		if c0&0x10 != 0 {
+			c ^= 0x1e4f43e470
+		}
+	}
+
+	return c ^ 1
+}
+
+// cashAddrPrefixExpand returns lower 5 bits of each prefix
+// char followed by a zero separator
+func cashAddrPrefixExpand(prefix string) []byte {
+	out := make([]byte, 0, len(prefix)+1)
+	for i := 0; i < len(prefix); i++ {
+		out = append(out, prefix[i]&0x1f)
+	}
+
+	return append(out, 0)
+}
+
+// encodeCashAddr encodes hash of the type as CashAddr with the prefix
+func encodeCashAddr(prefix string, addrType byte, hash []byte) (string, error) {
+	sizeBits := -1
+	for i, size := range cashAddrHashSizes {
+		if size == len(hash) {
+			sizeBits = i
+		}
+	}
+
+	if sizeBits < 0 {
+		return "", fmt.Errorf("invalid cashaddr hash length %d", len(hash))
+	}
+
+	payload, err := bech32.ConvertBits(append([]byte{addrType<<3 | byte(sizeBits)}, hash...), 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert cashaddr payload bits: %w", err)
+	}
+
+	values := append(cashAddrPrefixExpand(prefix), payload...)
+	polymod := cashAddrPolymod(append(values, 0, 0, 0, 0, 0, 0, 0, 0))
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteByte(':')
+	for _, d := range payload {
+		sb.WriteByte(bech32Chars[d])
+	}
+	for i := 0; i < 8; i++ {
+		sb.WriteByte(bech32Chars[(polymod>>uint(5*(7-i)))&0x1f])
+	}
+
+	return sb.String(), nil
+}
+
+// decodeCashAddr decodes CashAddr into its prefix, type and hash. Address
+// without prefix is checked against prefixes of all supported networks
+func decodeCashAddr(addr string) (string, byte, []byte, error) {
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		return "", 0, nil, fmt.Errorf("mixed case in cashaddr")
+	}
+	addr = strings.ToLower(addr)
+
+	var prefixes []string
+	if pos := strings.IndexByte(addr, ':'); pos >= 0 {
+		prefixes = []string{addr[:pos]}
+		addr = addr[pos+1:]
+	} else {
+		for _, prefix := range cashAddrPrefixes {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	data := make([]byte, 0, len(addr))
+	for i := 0; i < len(addr); i++ {
+		d := strings.IndexByte(bech32Chars, addr[i])
+		if d < 0 {
+			return "", 0, nil, fmt.Errorf("invalid character %q in cashaddr", addr[i])
+		}
+		data = append(data, byte(d))
+	}
+
+	if len(data) < 9 {
+		return "", 0, nil, fmt.Errorf("cashaddr data too short")
+	}
+
+	prefix := ""
+	for _, p := range prefixes {
+		if cashAddrPolymod(append(cashAddrPrefixExpand(p), data...)) == 0 {
+			prefix = p
+			break
+		}
+	}
+
+	if len(prefix) == 0 {
+		return "", 0, nil, fmt.Errorf("invalid cashaddr checksum")
+	}
+
+	payload, err := bech32.ConvertBits(data[:len(data)-8], 5, 8, false)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to convert cashaddr payload bits: %w", err)
+	}
+
+	version, hash := payload[0], payload[1:]
+	if version&0x80 != 0 {
+		return "", 0, nil, fmt.Errorf("invalid cashaddr version byte %d", version)
+	}
+
+	if size := cashAddrHashSizes[version&0x07]; size != len(hash) {
+		return "", 0, nil, fmt.Errorf("invalid cashaddr hash length %d, expected %d", len(hash), size)
+	}
+
+	return prefix, version >> 3, hash, nil
+}
+
+// IsCashAddr returns true if the address has a CashAddr prefix
+// of a supported network or decodes as CashAddr without prefix
+func IsCashAddr(addr string) bool {
+	lower := strings.ToLower(addr)
+	for _, prefix := range cashAddrPrefixes {
+		if strings.HasPrefix(lower, prefix+":") {
+			return true
+		}
+	}
+
+	_, _, _, err := decodeCashAddr(addr)
+	return err == nil
+}
+
+// DecodeCashAddr decodes bitcoin cash CashAddr and maps it back to the
+// legacy base58 address of the same hash
+func DecodeCashAddr(addr string) (*Key, error) {
+	prefix, addrType, hash, err := decodeCashAddr(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cashaddr: %w", err)
+	}
+
+	coin, err := CoinByName(CoinTypeBch)
+	if err != nil {
+		return nil, err
+	}
+
+	var network *CoinNetwork
+	for _, n := range coin.Networks {
+		if cashAddrPrefixes[n.Params] == prefix {
+			network = n
+		}
+	}
+
+	if network == nil {
+		return nil, fmt.Errorf("invalid or unsupported cashaddr prefix: %s", prefix)
+	}
+
+	var legacy btcutil.Address
+	switch addrType {
+	case cashAddrTypeP2pkh:
+		legacy, err = btcutil.NewAddressPubKeyHash(hash, network.Params)
+	case cashAddrTypeP2sh:
+		legacy, err = btcutil.NewAddressScriptHashFromHash(hash, network.Params)
+	default:
+		return nil, fmt.Errorf("invalid or unsupported cashaddr type %d", addrType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate legacy address: %w", err)
+	}
+
+	cashAddr, err := encodeCashAddr(prefix, addrType, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{
+		Addr:       cashAddr,
+		LegacyAddr: legacy.EncodeAddress(),
+		AddrType:   AddrTypeLegacy,
+		Network:    network.Network,
+		CoinType:   coin.Name,
+	}
+
+	if addrType == cashAddrTypeP2sh {
+		key.AddrType = AddrTypeP2sh
+	}
+
+	return key, nil
+}
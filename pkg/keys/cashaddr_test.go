@@ -0,0 +1,130 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+// TestDecodeCashAddr uses vectors from
+// https://github.com/bitcoincashorg/bitcoincash.org/blob/master/spec/cashaddr.md
+func TestDecodeCashAddr(t *testing.T) {
+	tests := []struct {
+		cashAddr   string
+		addr       string
+		legacyAddr string
+		addrType   string
+	}{
+		{
+			cashAddr:   "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",
+			addr:       "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",
+			legacyAddr: "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu",
+			addrType:   AddrTypeLegacy,
+		},
+		{
+			cashAddr:   "qr95sy3j9xwd2ap32xkykttr4cvcu7as4y0qverfuy",
+			addr:       "bitcoincash:qr95sy3j9xwd2ap32xkykttr4cvcu7as4y0qverfuy",
+			legacyAddr: "1KXrWXciRDZUpQwQmuM1DbwsKDLYAYsVLR",
+			addrType:   AddrTypeLegacy,
+		},
+		{
+			cashAddr:   "BITCOINCASH:PPM2QSZNHKS23Z7629MMS6S4CWEF74VCWVN0H829PQ",
+			addr:       "bitcoincash:ppm2qsznhks23z7629mms6s4cwef74vcwvn0h829pq",
+			legacyAddr: "3CWFddi6m4ndiGyKqzYvsFYagqDLPVMTzC",
+			addrType:   AddrTypeP2sh,
+		},
+	}
+
+	for _, test := range tests {
+		if !IsCashAddr(test.cashAddr) {
+			t.Fatal("expected cashaddr", test.cashAddr)
+		}
+
+		key, err := DecodeCashAddr(test.cashAddr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.Addr != test.addr || key.LegacyAddr != test.legacyAddr || key.AddrType != test.addrType {
+			t.Fatal("expected", test.addr, test.legacyAddr, test.addrType, ", got", key.Addr, key.LegacyAddr, key.AddrType)
+		}
+
+		if key.Network != NetworkTypeMainnet || key.CoinType != CoinTypeBch {
+			t.Fatal("unexpected network or coin", key.Network, key.CoinType)
+		}
+	}
+
+	invalid := []string{
+		"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6c",
+		"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6A",
+		"bchtest:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",
+		"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdxb",
+	}
+
+	for _, addr := range invalid {
+		if _, err := DecodeCashAddr(addr); err == nil {
+			t.Fatal("expected error for", addr)
+		}
+	}
+
+	if IsCashAddr("1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu") {
+		t.Fatal("expected legacy address not to be cashaddr")
+	}
+}
+
+func TestNew_Bch(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	key, err := New(&Config{
+		Seed:           seed,
+		Network:        NetworkTypeMainnet,
+		DerivationPath: "auto",
+		AddrType:       AddrTypeLegacy,
+		Coin:           CoinTypeBch,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key.DerivationPath != "m/44h/145h/0h/0/0" {
+		t.Fatal("expected m/44h/145h/0h/0/0, got", key.DerivationPath)
+	}
+
+	if expected := "bitcoincash:qqyx49mu0kkn9ftfj6hje6g2wfer34yfnq5tahq3q6"; key.Addr != expected {
+		t.Fatal("expected", expected, ", got", key.Addr)
+	}
+
+	decoded, err := DecodeCashAddr(key.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	btc, err := DecodePublicHex(key.PubKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.LegacyAddr != btc.Addr {
+		t.Fatal("expected", btc.Addr, ", got", decoded.LegacyAddr)
+	}
+
+	testnet, err := New(&Config{
+		Seed:           seed,
+		Network:        NetworkTypeTestnet,
+		DerivationPath: "auto",
+		AddrType:       AddrTypeLegacy,
+		Coin:           CoinTypeBch,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err = DecodeCashAddr(testnet.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Network != NetworkTypeTestnet || decoded.LegacyAddr[0] != 'm' && decoded.LegacyAddr[0] != 'n' {
+		t.Fatal("unexpected testnet address", testnet.Addr, decoded.LegacyAddr)
+	}
+}
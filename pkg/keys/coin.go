@@ -0,0 +1,469 @@
+package keys
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+)
+
+// Coin represents a coin registered by its SLIP-44 index along with
+// network params, extended key versions per address type and the
+// address encoder used for its keys. Network params carry address
+// prefixes as well as the wif prefix of the coin
+// https://github.com/satoshilabs/slips/blob/master/slip-0044.md
+type Coin struct {
+	Name string
+	// Index is the coin type used in default derivation paths
+	// on mainnet, test networks use coin type 1 for all coins
+	Index    uint32
+	Networks []*CoinNetwork
+	// EncodeAddr encodes single key address of the address type,
+	// bitcoin address encoding is used when nil
+	EncodeAddr func(pubKey []byte, addrType string, params *chaincfg.Params) (string, error)
+}
+
+// CoinNetwork represents network params and extended key versions
+// of a coin on a network
+type CoinNetwork struct {
+	Network  string
+	Params   *chaincfg.Params
+	Versions []KeyVersion
+}
+
+// KeyVersion represents hex encoded public and private extended
+// key versions for an address type
+type KeyVersion struct {
+	AddrType string
+	Pub      string
+	Prv      string
+}
+
+// coins is the coin registry in the order of registration
+var coins []*Coin
+
+var (
+	keyVersions       map[string][]byte
+	versionToVersions map[string][]string
+	versionToAddrType map[string]string
+	versionToNetwork  map[string]string
+	versionToCoin     map[string]*Coin
+)
+
+// RegisterCoin adds the coin to the registry. Key versions shared with
+// previously registered coins or address types resolve to the earlier
+// registration when detecting coin and address type of input keys
+func RegisterCoin(coin *Coin) error {
+	coin.Name = strings.ToLower(coin.Name)
+	for _, c := range coins {
+		if c.Name == coin.Name {
+			return fmt.Errorf("coin %s is already registered", coin.Name)
+		}
+		if c.Index == coin.Index {
+			return fmt.Errorf("coin index %d is already registered for %s", coin.Index, c.Name)
+		}
+	}
+
+	for _, network := range coin.Networks {
+		if network.Params == nil {
+			return fmt.Errorf("missing network params for %s %s", coin.Name, network.Network)
+		}
+
+		for _, version := range network.Versions {
+			if len(version.Pub) != 8 || len(version.Prv) != 8 {
+				return fmt.Errorf("invalid key versions for %s %s %s", coin.Name, network.Network, version.AddrType)
+			}
+		}
+	}
+
+	for _, network := range coin.Networks {
+		for _, version := range network.Versions {
+			keyVersions[path.Join(coin.Name, network.Network, version.AddrType, KeyTypePub)] = mustDecodeHex(version.Pub)
+			keyVersions[path.Join(coin.Name, network.Network, version.AddrType, KeyTypePrv)] = mustDecodeHex(version.Prv)
+
+			for _, v := range []string{version.Pub, version.Prv} {
+				if _, ok := versionToVersions[v]; ok {
+					continue
+				}
+
+				versionToVersions[v] = []string{version.Pub, version.Prv}
+				versionToAddrType[v] = version.AddrType
+				versionToNetwork[v] = network.Network
+				versionToCoin[v] = coin
+			}
+		}
+	}
+
+	coins = append(coins, coin)
+
+	return nil
+}
+
+func mustRegisterCoin(coin *Coin) {
+	if err := RegisterCoin(coin); err != nil {
+		panic(err)
+	}
+}
+
+// CoinByName returns registered coin by its name
+func CoinByName(name string) (*Coin, error) {
+	if len(name) == 0 {
+		name = CoinTypeBtc
+	}
+
+	name = strings.ToLower(name)
+	for _, coin := range coins {
+		if coin.Name == name {
+			return coin, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid or unsupported coin: %s. allowed coins are %v", name, CoinNames())
+}
+
+// CoinNames returns names of registered coins
+func CoinNames() []string {
+	names := make([]string, len(coins))
+	for i, coin := range coins {
+		names[i] = coin.Name
+	}
+
+	return names
+}
+
+// network returns coin network by its name
+func (c *Coin) network(network string) (*CoinNetwork, error) {
+	for _, n := range c.Networks {
+		if n.Network == network {
+			return n, nil
+		}
+	}
+
+	names := make([]string, len(c.Networks))
+	for i, n := range c.Networks {
+		names[i] = n.Network
+	}
+
+	return nil, fmt.Errorf("invalid or unsupported network: %s. allowed networks for %s are %v", network, c.Name, names)
+}
+
+// hasVersion returns true if the hex encoded key version
+// belongs to the network
+func (n *CoinNetwork) hasVersion(version string) bool {
+	for _, v := range n.Versions {
+		if v.Pub == version || v.Prv == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// versionOf returns network and address type of the coin for the
+// hex encoded key version
+func (c *Coin) versionOf(version string) (string, string, bool) {
+	for _, n := range c.Networks {
+		for _, v := range n.Versions {
+			if v.Pub == version || v.Prv == version {
+				return n.Network, v.AddrType, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// coinIndex returns coin type for default derivation paths
+// on the network
+func (c *Coin) coinIndex(network string) uint32 {
+	if network == NetworkTypeMainnet {
+		return c.Index
+	}
+
+	return 1
+}
+
+// encodeAddr encodes single key address using the coin
+// address encoder
+func (c *Coin) encodeAddr(pubKey []byte, addrType string, params *chaincfg.Params) (string, error) {
+	if c.EncodeAddr != nil {
+		return c.EncodeAddr(pubKey, addrType, params)
+	}
+
+	return singleKeyAddr(pubKey, addrType, params)
+}
+
+// coinParams returns network params of the coin on the network
+func coinParams(coinType, network string) (*chaincfg.Params, error) {
+	coin, err := CoinByName(coinType)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := coin.network(network)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.Params, nil
+}
+
+// wifCoin returns coin and its network for the wif prefix. Prefixes
+// shared by multiple coins resolve to the coin registered first
+func wifCoin(wif *btcutil.WIF) (*Coin, *CoinNetwork, error) {
+	for _, coin := range coins {
+		for _, network := range coin.Networks {
+			if wif.IsForNet(network.Params) {
+				return coin, network, nil
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("detected network is not supported, accepted coins are %v", CoinNames())
+}
+
+// https://github.com/litecoin-project/litecoin/blob/master/src/chainparams.cpp
+var (
+	litecoinMainNetParams = chaincfg.Params{
+		Name:             "litecoin-mainnet",
+		Bech32HRPSegwit:  "ltc",
+		PubKeyHashAddrID: 0x30,
+		ScriptHashAddrID: 0x32,
+		PrivateKeyID:     0xb0,
+		HDPublicKeyID:    [4]byte{0x01, 0x9d, 0xa4, 0x62},
+		HDPrivateKeyID:   [4]byte{0x01, 0x9d, 0x9c, 0xfe},
+		HDCoinType:       2,
+	}
+
+	litecoinTestNetParams = chaincfg.Params{
+		Name:             "litecoin-testnet",
+		Bech32HRPSegwit:  "tltc",
+		PubKeyHashAddrID: 0x6f,
+		ScriptHashAddrID: 0x3a,
+		PrivateKeyID:     0xef,
+		HDPublicKeyID:    [4]byte{0x04, 0x36, 0xf6, 0xe1},
+		HDPrivateKeyID:   [4]byte{0x04, 0x36, 0xef, 0x7d},
+		HDCoinType:       1,
+	}
+)
+
+// https://github.com/dogecoin/dogecoin/blob/master/src/chainparams.cpp
+var (
+	dogecoinMainNetParams = chaincfg.Params{
+		Name:             "dogecoin-mainnet",
+		PubKeyHashAddrID: 0x1e,
+		ScriptHashAddrID: 0x16,
+		PrivateKeyID:     0x9e,
+		HDPublicKeyID:    [4]byte{0x02, 0xfa, 0xca, 0xfd},
+		HDPrivateKeyID:   [4]byte{0x02, 0xfa, 0xc3, 0x98},
+		HDCoinType:       3,
+	}
+
+	dogecoinTestNetParams = chaincfg.Params{
+		Name:             "dogecoin-testnet",
+		PubKeyHashAddrID: 0x71,
+		ScriptHashAddrID: 0xc4,
+		PrivateKeyID:     0xf1,
+		HDPublicKeyID:    [4]byte{0x04, 0x32, 0xa9, 0xa8},
+		HDPrivateKeyID:   [4]byte{0x04, 0x32, 0xa2, 0x43},
+		HDCoinType:       1,
+	}
+)
+
+// https://github.com/dashpay/dash/blob/master/src/chainparams.cpp
+var (
+	dashMainNetParams = chaincfg.Params{
+		Name:             "dash-mainnet",
+		PubKeyHashAddrID: 0x4c,
+		ScriptHashAddrID: 0x10,
+		PrivateKeyID:     0xcc,
+		HDPublicKeyID:    [4]byte{0x02, 0xfe, 0x52, 0xf8},
+		HDPrivateKeyID:   [4]byte{0x02, 0xfe, 0x52, 0xcc},
+		HDCoinType:       5,
+	}
+
+	dashTestNetParams = chaincfg.Params{
+		Name:             "dash-testnet",
+		PubKeyHashAddrID: 0x8c,
+		ScriptHashAddrID: 0x13,
+		PrivateKeyID:     0xef,
+		HDPublicKeyID:    [4]byte{0x3a, 0x80, 0x61, 0xa0},
+		HDPrivateKeyID:   [4]byte{0x3a, 0x80, 0x58, 0x37},
+		HDCoinType:       1,
+	}
+)
+
+func init() {
+	keyVersions = make(map[string][]byte)
+	versionToVersions = make(map[string][]string)
+	versionToAddrType = make(map[string]string)
+	versionToNetwork = make(map[string]string)
+	versionToCoin = make(map[string]*Coin)
+
+	// btc is registered first so that key versions and wif prefixes
+	// shared with other coins resolve to btc
+	// https://electrum.readthedocs.io/en/latest/xpub_version_bytes.html#specification
+	mustRegisterCoin(&Coin{
+		Name:  CoinTypeBtc,
+		Index: 0,
+		Networks: []*CoinNetwork{
+			{
+				Network: NetworkTypeMainnet,
+				Params:  netParams[NetworkTypeMainnet],
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: xpub, Prv: xprv},
+					{AddrType: AddrTypeP2wpkhP2sh, Pub: ypub, Prv: yprv},
+					{AddrType: AddrTypeP2wshP2sh, Pub: Ypub, Prv: Yprv},
+					{AddrType: AddrTypeP2wpkh, Pub: zpub, Prv: zprv},
+					{AddrType: AddrTypeP2wsh, Pub: Zpub, Prv: Zprv},
+					{AddrType: AddrTypeP2tr, Pub: xpub, Prv: xprv},
+				},
+			},
+			{
+				Network: NetworkTypeTestnet,
+				Params:  netParams[NetworkTypeTestnet],
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: tpub, Prv: tprv},
+					{AddrType: AddrTypeP2wpkhP2sh, Pub: upub, Prv: uprv},
+					{AddrType: AddrTypeP2wshP2sh, Pub: Upub, Prv: Uprv},
+					{AddrType: AddrTypeP2wpkh, Pub: vpub, Prv: vprv},
+					{AddrType: AddrTypeP2wsh, Pub: Vpub, Prv: Vprv},
+					{AddrType: AddrTypeP2tr, Pub: tpub, Prv: tprv},
+				},
+			},
+			// signet and regtest share key versions with testnet, which
+			// resolve to testnet when detecting network of input keys
+			{
+				Network: NetworkTypeSignet,
+				Params:  netParams[NetworkTypeSignet],
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: tpub, Prv: tprv},
+					{AddrType: AddrTypeP2wpkhP2sh, Pub: upub, Prv: uprv},
+					{AddrType: AddrTypeP2wshP2sh, Pub: Upub, Prv: Uprv},
+					{AddrType: AddrTypeP2wpkh, Pub: vpub, Prv: vprv},
+					{AddrType: AddrTypeP2wsh, Pub: Vpub, Prv: Vprv},
+					{AddrType: AddrTypeP2tr, Pub: tpub, Prv: tprv},
+				},
+			},
+			{
+				Network: NetworkTypeRegtest,
+				Params:  netParams[NetworkTypeRegtest],
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: tpub, Prv: tprv},
+					{AddrType: AddrTypeP2wpkhP2sh, Pub: upub, Prv: uprv},
+					{AddrType: AddrTypeP2wshP2sh, Pub: Upub, Prv: Uprv},
+					{AddrType: AddrTypeP2wpkh, Pub: vpub, Prv: vprv},
+					{AddrType: AddrTypeP2wsh, Pub: Vpub, Prv: Vprv},
+					{AddrType: AddrTypeP2tr, Pub: tpub, Prv: tprv},
+				},
+			},
+		},
+	})
+
+	// https://github.com/satoshilabs/slips/blob/master/slip-0132.md
+	mustRegisterCoin(&Coin{
+		Name:  CoinTypeLtc,
+		Index: 2,
+		Networks: []*CoinNetwork{
+			{
+				Network: NetworkTypeMainnet,
+				Params:  &litecoinMainNetParams,
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: ltub, Prv: ltpv},
+					{AddrType: AddrTypeP2wpkhP2sh, Pub: mtub, Prv: mtpv},
+				},
+			},
+			{
+				Network: NetworkTypeTestnet,
+				Params:  &litecoinTestNetParams,
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: ttub, Prv: ttpv},
+				},
+			},
+		},
+	})
+
+	mustRegisterCoin(&Coin{
+		Name:  CoinTypeDoge,
+		Index: 3,
+		Networks: []*CoinNetwork{
+			{
+				Network: NetworkTypeMainnet,
+				Params:  &dogecoinMainNetParams,
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: dgub, Prv: dgpv},
+				},
+			},
+			{
+				Network: NetworkTypeTestnet,
+				Params:  &dogecoinTestNetParams,
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: tgub, Prv: tgpv},
+				},
+			},
+		},
+	})
+
+	mustRegisterCoin(&Coin{
+		Name:  CoinTypeDash,
+		Index: 5,
+		Networks: []*CoinNetwork{
+			{
+				Network: NetworkTypeMainnet,
+				Params:  &dashMainNetParams,
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: drkv, Prv: drkp},
+				},
+			},
+			{
+				Network: NetworkTypeTestnet,
+				Params:  &dashTestNetParams,
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: drkV, Prv: drkP},
+				},
+			},
+		},
+	})
+
+	// ethereum keys use xpub and xprv versions, which resolve to btc
+	// when detecting coin of input keys unless coin is provided
+	mustRegisterCoin(&Coin{
+		Name:  CoinTypeEth,
+		Index: 60,
+		Networks: []*CoinNetwork{
+			{
+				Network: NetworkTypeMainnet,
+				Params:  &ethereumMainNetParams,
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeEth, Pub: xpub, Prv: xprv},
+				},
+			},
+		},
+		EncodeAddr: ethEncodeAddr,
+	})
+
+	// bitcoin cash keys share versions and wif prefixes with btc
+	mustRegisterCoin(&Coin{
+		Name:  CoinTypeBch,
+		Index: 145,
+		Networks: []*CoinNetwork{
+			{
+				Network: NetworkTypeMainnet,
+				Params:  &bitcoinCashMainNetParams,
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: xpub, Prv: xprv},
+				},
+			},
+			{
+				Network: NetworkTypeTestnet,
+				Params:  &bitcoinCashTestNetParams,
+				Versions: []KeyVersion{
+					{AddrType: AddrTypeP2pkhOrP2sh, Pub: tpub, Prv: tprv},
+				},
+			},
+		},
+		EncodeAddr: bchEncodeAddr,
+	})
+}
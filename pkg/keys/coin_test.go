@@ -0,0 +1,163 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+func TestNew_Coins(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	tests := []struct {
+		coin           string
+		network        string
+		addrType       string
+		derivationPath string
+		addr           string
+		xPrv           string
+		wif            string
+	}{
+		{
+			coin:           CoinTypeBtc,
+			network:        NetworkTypeMainnet,
+			addrType:       AddrTypeLegacy,
+			derivationPath: "m/44h/0h/0h/0/0",
+			addr:           "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA",
+			xPrv:           "xprv",
+			wif:            "L",
+		},
+		{
+			coin:           CoinTypeLtc,
+			network:        NetworkTypeMainnet,
+			addrType:       AddrTypeLegacy,
+			derivationPath: "m/44h/2h/0h/0/0",
+			addr:           "LUWPbpM43E2p7ZSh8cyTBEkvpHmr3cB8Ez",
+			xPrv:           "Ltpv",
+			wif:            "T",
+		},
+		{
+			coin:           CoinTypeLtc,
+			network:        NetworkTypeMainnet,
+			addrType:       AddrTypeBip49,
+			derivationPath: "m/49h/2h/0h/0/0",
+			addr:           "M",
+			xPrv:           "Mtpv",
+			wif:            "T",
+		},
+		{
+			coin:           CoinTypeDoge,
+			network:        NetworkTypeMainnet,
+			addrType:       AddrTypeLegacy,
+			derivationPath: "m/44h/3h/0h/0/0",
+			addr:           "DBus3bamQjgJULBJtYXpEzDWQRwF5iwxgC",
+			xPrv:           "dgpv",
+			wif:            "Q",
+		},
+		{
+			coin:           CoinTypeDoge,
+			network:        NetworkTypeTestnet,
+			addrType:       AddrTypeLegacy,
+			derivationPath: "m/44h/1h/0h/0/0",
+			addr:           "n",
+			xPrv:           "tgpv",
+			wif:            "c",
+		},
+		{
+			coin:           CoinTypeDash,
+			network:        NetworkTypeMainnet,
+			addrType:       AddrTypeLegacy,
+			derivationPath: "m/44h/5h/0h/0/0",
+			addr:           "X",
+			xPrv:           "drkp",
+			wif:            "X",
+		},
+	}
+
+	for _, test := range tests {
+		key, err := New(&Config{
+			Seed:           seed,
+			Network:        test.network,
+			DerivationPath: "auto",
+			AddrType:       test.addrType,
+			Coin:           test.coin,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.DerivationPath != test.derivationPath {
+			t.Fatal("expected", test.derivationPath, ", got", key.DerivationPath, ", for", test.coin)
+		}
+
+		if !strings.HasPrefix(key.Addr, test.addr) {
+			t.Fatal("expected addr", test.addr, ", got", key.Addr, ", for", test.coin)
+		}
+
+		if !strings.HasPrefix(key.XPrv, test.xPrv) {
+			t.Fatal("expected", test.xPrv, ", got", key.XPrv, ", for", test.coin)
+		}
+
+		if !strings.HasPrefix(key.PrvKeyWif, test.wif) {
+			t.Fatal("expected wif prefix", test.wif, ", got", key.PrvKeyWif, ", for", test.coin)
+		}
+
+		if key.CoinType != test.coin || key.Network != test.network {
+			t.Fatal("expected", test.coin, test.network, ", got", key.CoinType, key.Network)
+		}
+
+		// coin is detected from the extended key version
+		derived, err := Derive(key.XPrv, "m")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if derived.Addr != key.Addr || derived.CoinType != test.coin {
+			t.Fatal("expected", key.Addr, test.coin, ", got", derived.Addr, derived.CoinType)
+		}
+
+		if err := Validate(key.XPub); err != nil {
+			t.Fatal(err)
+		}
+
+		// dogecoin testnet wif prefix is not shared with btc
+		if test.network == NetworkTypeMainnet || test.coin == CoinTypeDoge {
+			decoded, err := DecodePrivateWifKey(key.PrvKeyWif)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded.CoinType != test.coin || decoded.Network != test.network {
+				t.Fatal("expected", test.coin, test.network, ", got", decoded.CoinType, decoded.Network)
+			}
+
+			if test.addrType == AddrTypeLegacy && decoded.Addr != key.Addr {
+				t.Fatal("expected", key.Addr, ", got", decoded.Addr)
+			}
+		}
+	}
+}
+
+func TestNew_CoinUnsupported(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	configs := []*Config{
+		{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "auto", AddrType: AddrTypeBip84, Coin: CoinTypeDoge},
+		{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "auto", AddrType: AddrTypeLegacy, Coin: "xyz"},
+	}
+
+	for _, config := range configs {
+		if _, err := New(config); err == nil {
+			t.Fatal("expected error for", config.Coin, config.AddrType)
+		}
+	}
+
+	if err := RegisterCoin(&Coin{Name: "BTC", Index: 1000}); err == nil {
+		t.Fatal("expected error for duplicate coin name")
+	}
+
+	if err := RegisterCoin(&Coin{Name: "xyz", Index: 2}); err == nil {
+		t.Fatal("expected error for duplicate coin index")
+	}
+}
@@ -8,10 +8,17 @@ const (
 const (
 	NetworkTypeMainnet = "mainnet"
 	NetworkTypeTestnet = "testnet"
+	NetworkTypeSignet  = "signet"
+	NetworkTypeRegtest = "regtest"
 )
 
 const (
-	CoinTypeBtc = "btc"
+	CoinTypeBtc  = "btc"
+	CoinTypeLtc  = "ltc"
+	CoinTypeDoge = "doge"
+	CoinTypeDash = "dash"
+	CoinTypeEth  = "eth"
+	CoinTypeBch  = "bch"
 )
 
 const (
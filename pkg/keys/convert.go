@@ -0,0 +1,138 @@
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/tyler-smith/go-bip32"
+)
+
+// versionNames maps extended key prefixes to their key versions
+// https://github.com/satoshilabs/slips/blob/master/slip-0132.md
+var versionNames = map[string]string{
+	"xpub": xpub,
+	"xprv": xprv,
+	"tpub": tpub,
+	"tprv": tprv,
+	"ypub": ypub,
+	"yprv": yprv,
+	"upub": upub,
+	"uprv": uprv,
+	"Ypub": Ypub,
+	"Yprv": Yprv,
+	"Upub": Upub,
+	"Uprv": Uprv,
+	"zpub": zpub,
+	"zprv": zprv,
+	"vpub": vpub,
+	"vprv": vprv,
+	"Zpub": Zpub,
+	"Zprv": Zprv,
+	"Vpub": Vpub,
+	"Vprv": Vprv,
+	"Ltub": ltub,
+	"Ltpv": ltpv,
+	"Mtub": mtub,
+	"Mtpv": mtpv,
+	"ttub": ttub,
+	"ttpv": ttpv,
+	"dgub": dgub,
+	"dgpv": dgpv,
+	"tgub": tgub,
+	"tgpv": tgpv,
+	"drkv": drkv,
+	"drkp": drkp,
+	"DRKV": drkV,
+	"DRKP": drkP,
+}
+
+// ConvertedKey is the output of extended key version conversion
+type ConvertedKey struct {
+	Key          string   `json:"key" yaml:"key"`
+	From         string   `json:"from" yaml:"from"`
+	To           string   `json:"to" yaml:"to"`
+	FromAddrType string   `json:"fromAddrType" yaml:"fromAddrType"`
+	ToAddrType   string   `json:"toAddrType" yaml:"toAddrType"`
+	Network      string   `json:"network" yaml:"network"`
+	CoinType     string   `json:"coinType" yaml:"coinType"`
+	Warnings     []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// VersionNames returns sorted list of extended key prefixes
+// that keys can be converted to
+func VersionNames() []string {
+	names := make([]string, 0, len(versionNames))
+	for name := range versionNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Convert serializes the extended key using the key version of the prefix,
+// such as zpub, leaving the key material unchanged. Public keys can only be
+// converted to public key versions and private keys to private key versions
+// of the same network. Warnings are returned when the conversion changes
+// the script type or the coin implied by the key version
+func Convert(keyString, to string) (*ConvertedKey, error) {
+	if err := Validate(keyString); err != nil {
+		return nil, fmt.Errorf("failed to validate key: %w", err)
+	}
+
+	key, err := bip32.B58Deserialize(keyString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize key: %w", err)
+	}
+
+	fromVersion := hex.EncodeToString(key.Version)
+	toVersion, ok := versionNames[to]
+	if !ok {
+		return nil, fmt.Errorf("invalid or unsupported key version: %s. allowed versions are %v", to, VersionNames())
+	}
+
+	// version prefix is the first four chars of the serialized key
+	from := keyString[:4]
+
+	// version tuples list public key version first
+	toPrivate := versionToVersions[toVersion][1] == toVersion
+	switch {
+	case key.IsPrivate && !toPrivate:
+		return nil, fmt.Errorf("cannot convert private key to public key version %s", to)
+	case !key.IsPrivate && toPrivate:
+		return nil, fmt.Errorf("cannot convert public key to private key version %s", to)
+	}
+
+	fromNetwork, toNetwork := versionToNetwork[fromVersion], versionToNetwork[toVersion]
+	if fromNetwork != toNetwork {
+		return nil, fmt.Errorf("cannot convert %s key to %s key version %s", fromNetwork, toNetwork, to)
+	}
+
+	converted := &ConvertedKey{
+		From:         from,
+		To:           to,
+		FromAddrType: versionToAddrType[fromVersion],
+		ToAddrType:   versionToAddrType[toVersion],
+		Network:      toNetwork,
+		CoinType:     versionToCoin[toVersion].Name,
+	}
+
+	if converted.FromAddrType != converted.ToAddrType {
+		converted.Warnings = append(converted.Warnings,
+			fmt.Sprintf("conversion changes implied script type from %s to %s, addresses derived from converted key will differ",
+				converted.FromAddrType, converted.ToAddrType),
+		)
+	}
+
+	if fromCoin := versionToCoin[fromVersion].Name; fromCoin != converted.CoinType {
+		converted.Warnings = append(converted.Warnings,
+			fmt.Sprintf("conversion changes implied coin from %s to %s", fromCoin, converted.CoinType),
+		)
+	}
+
+	key.Version = mustDecodeHex(toVersion)
+	converted.Key = key.String()
+
+	return converted, nil
+}
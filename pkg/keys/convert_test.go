@@ -0,0 +1,63 @@
+package keys
+
+import (
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	// BIP-84 account key of mnemonic abandon ... about
+	zpubKey := "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+
+	converted, err := Convert(zpubKey, "xpub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if converted.From != "zpub" || converted.ToAddrType != AddrTypeP2pkhOrP2sh || len(converted.Warnings) != 1 {
+		t.Fatal("unexpected conversion", converted)
+	}
+
+	// round trip retains key material
+	back, err := Convert(converted.Key, "zpub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if back.Key != zpubKey {
+		t.Fatal("expected", zpubKey, ", got", back.Key)
+	}
+
+	same, err := Convert(zpubKey, "zpub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if same.Key != zpubKey || len(same.Warnings) != 0 {
+		t.Fatal("expected no warnings for same version", same.Warnings)
+	}
+
+	// converted key derives same public keys
+	original, err := Derive(zpubKey, "m/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derived, err := Derive(converted.Key, "m/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if original.PubKeyHex != derived.PubKeyHex || original.Addr == derived.Addr {
+		t.Fatal("expected same pub key and different address", original.Addr, derived.Addr)
+	}
+
+	for _, to := range []string{"zprv", "vpub", "tpub", "ZPUB", "abcd"} {
+		if _, err := Convert(zpubKey, to); err == nil {
+			t.Fatal("expected error for", to)
+		}
+	}
+
+	if _, err := Convert(zpubKey[:len(zpubKey)-1]+"t", "xpub"); err == nil {
+		t.Fatal("expected error for invalid checksum")
+	}
+}
@@ -0,0 +1,674 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/tyler-smith/go-bip32"
+)
+
+// output script descriptor functions per
+// https://github.com/bitcoin/bips/blob/master/bip-0380.mediawiki
+const (
+	DescriptorPkh         = "pkh"
+	DescriptorWpkh        = "wpkh"
+	DescriptorSh          = "sh"
+	DescriptorWsh         = "wsh"
+	DescriptorTr          = "tr"
+	DescriptorMulti       = "multi"
+	DescriptorSortedMulti = "sortedmulti"
+)
+
+const (
+	descriptorInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	descriptorChecksumLength  = 8
+)
+
+var descriptorGen = []uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+
+func descriptorPolymod(symbols []uint64) uint64 {
+	chk := uint64(1)
+	for _, value := range symbols {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ value
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= descriptorGen[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+// DescriptorChecksum computes BIP-380 checksum of the descriptor
+// that is provided without the checksum
+func DescriptorChecksum(desc string) (string, error) {
+	var symbols, groups []uint64
+	for _, c := range desc {
+		v := strings.IndexRune(descriptorInputCharset, c)
+		if v < 0 {
+			return "", fmt.Errorf("invalid character %q in descriptor", c)
+		}
+
+		symbols = append(symbols, uint64(v&31))
+		groups = append(groups, uint64(v>>5))
+		if len(groups) == 3 {
+			symbols = append(symbols, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+
+	switch len(groups) {
+	case 1:
+		symbols = append(symbols, groups[0])
+	case 2:
+		symbols = append(symbols, groups[0]*3+groups[1])
+	}
+
+	symbols = append(symbols, make([]uint64, descriptorChecksumLength)...)
+	chk := descriptorPolymod(symbols) ^ 1
+
+	checksum := make([]byte, descriptorChecksumLength)
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(chk>>uint(5*(7-i)))&31]
+	}
+
+	return string(checksum), nil
+}
+
+// addDescriptorChecksum returns descriptor with checksum appended
+func addDescriptorChecksum(desc string) (string, error) {
+	checksum, err := DescriptorChecksum(desc)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s#%s", desc, checksum), nil
+}
+
+// fingerprintOf returns first four bytes of hash160 of the public key
+func fingerprintOf(key *bip32.Key) ([]byte, error) {
+	pubKey := key
+	if key.IsPrivate {
+		p, err := publicKeyOf(key)
+		if err != nil {
+			return nil, err
+		}
+		pubKey = p
+	}
+
+	return btcutil.Hash160(pubKey.Key)[:4], nil
+}
+
+// networkOf returns the network implied by the extended key version
+func networkOf(key *bip32.Key) (string, error) {
+	if network, ok := versionToNetwork[hex.EncodeToString(key.Version)]; ok {
+		return network, nil
+	}
+
+	return "", fmt.Errorf("unsupported network and/or coin type, accepted values are %v", CoinNames())
+}
+
+// descriptorFor returns output descriptor for keys derived from the root key
+// along the derivation path. Key origin consists of the origin of the root key,
+// i.e., its own fingerprint for master keys or the parent fingerprint and child
+// index otherwise, and the hardened part of the derivation path. Remaining
+// non-hardened part is appended to the extended public key with its last index
+// replaced by a wildcard so that the descriptor covers all sibling addresses.
+// Empty descriptor is returned for address types that cannot be described
+func descriptorFor(g *keyGenerator, indices []uint32) (string, error) {
+	root, addrType, script := g.rootKey, g.addrType, g.script
+	hardened := hardenedLen(indices)
+
+	xKey, err := deriveChildKeys(root, indices[:hardened])
+	if err != nil {
+		return "", fmt.Errorf("failed to derive key origin: %w", err)
+	}
+
+	xKey, err = publicKeyOf(xKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	xPub, err := descriptorXPub(xKey)
+	if err != nil {
+		return "", err
+	}
+
+	var keyExpr strings.Builder
+	if hardened > 0 || len(g.originPath) > 0 {
+		keyExpr.WriteString(g.keyOrigin(indices[:hardened]))
+	}
+
+	keyExpr.WriteString(xPub)
+	keyExpr.WriteString(descriptorWildcardPath(indices[hardened:]))
+
+	var desc string
+	switch addrType {
+	case AddrTypeP2pkhOrP2sh:
+		desc = fmt.Sprintf("%s(%s)", DescriptorPkh, keyExpr.String())
+	case AddrTypeP2wpkhP2sh:
+		desc = fmt.Sprintf("%s(%s(%s))", DescriptorSh, DescriptorWpkh, keyExpr.String())
+	case AddrTypeP2wpkh:
+		desc = fmt.Sprintf("%s(%s)", DescriptorWpkh, keyExpr.String())
+	case AddrTypeP2tr:
+		desc = fmt.Sprintf("%s(%s)", DescriptorTr, keyExpr.String())
+	case AddrTypeP2wsh, AddrTypeP2wshP2sh:
+		// explicit witness scripts cannot be described generically
+		if script == nil || len(script.WitnessScript) > 0 {
+			return "", nil
+		}
+
+		args := []string{fmt.Sprintf("%d", script.Threshold), keyExpr.String()}
+		for _, p := range script.PubKeys {
+			pub, err := btcec.ParsePubKey(p, btcec.S256())
+			if err != nil {
+				return "", fmt.Errorf("failed to parse cosigner pub key %x: %w", p, err)
+			}
+			args = append(args, hex.EncodeToString(pub.SerializeCompressed()))
+		}
+
+		// cosigner extended keys are ranged along the same path
+		for _, key := range script.xKeys {
+			xPub, err := descriptorXPub(key.xKey)
+			if err != nil {
+				return "", err
+			}
+
+			var origin string
+			if len(key.fingerprint) > 0 {
+				origin = fmt.Sprintf("[%s%s]", key.fingerprint,
+					strings.TrimPrefix(FormatDerivationPath(key.origin), "m"))
+			}
+
+			args = append(args, origin+xPub+descriptorWildcardPath(indices[hardened:]))
+		}
+
+		desc = fmt.Sprintf("%s(%s(%s))", DescriptorWsh, DescriptorSortedMulti, strings.Join(args, ","))
+		if addrType == AddrTypeP2wshP2sh {
+			desc = fmt.Sprintf("%s(%s)", DescriptorSh, desc)
+		}
+	default:
+		return "", nil
+	}
+
+	return addDescriptorChecksum(desc)
+}
+
+// descriptorXPub returns extended public key in xpub or tpub version
+// since descriptors only allow these key versions
+func descriptorXPub(xKey *bip32.Key) (string, error) {
+	network, err := networkOf(xKey)
+	if err != nil {
+		return "", err
+	}
+
+	pubKey := *xKey
+	pubKey.Version = keyVersions[path.Join(CoinTypeBtc, network, AddrTypeP2pkhOrP2sh, KeyTypePub)]
+
+	return pubKey.String(), nil
+}
+
+// descriptorWildcardPath returns non-hardened derivation path suffix
+// with its last index replaced by a wildcard
+func descriptorWildcardPath(suffix []uint32) string {
+	if len(suffix) == 0 {
+		return ""
+	}
+
+	return strings.TrimPrefix(FormatDerivationPath(suffix[:len(suffix)-1]), "m") + "/*"
+}
+
+// Descriptor is a parsed output script descriptor. Supported descriptors are
+// pkh, wpkh, sh(wpkh), tr with key path only, and multi or sortedmulti
+// nested in sh, wsh or sh(wsh)
+type Descriptor struct {
+	desc      string
+	wrappers  []string
+	script    string
+	keys      []*descriptorKey
+	threshold int
+	network   string
+}
+
+// descriptorKey is a key expression within a descriptor
+type descriptorKey struct {
+	fingerprint      string
+	origin           []uint32
+	xKey             *bip32.Key
+	pubKey           []byte
+	indices          []uint32
+	wildcard         bool
+	hardenedWildcard bool
+}
+
+// ParseDescriptor parses output script descriptor verifying
+// the checksum if present
+func ParseDescriptor(desc string) (*Descriptor, error) {
+	desc = strings.TrimSpace(desc)
+	if i := strings.LastIndex(desc, "#"); i >= 0 {
+		checksum, err := DescriptorChecksum(desc[:i])
+		if err != nil {
+			return nil, err
+		}
+
+		if checksum != desc[i+1:] {
+			return nil, fmt.Errorf("invalid descriptor checksum %s, expected %s", desc[i+1:], checksum)
+		}
+
+		desc = desc[:i]
+	}
+
+	d := &Descriptor{desc: desc}
+
+	expr := desc
+	for {
+		name, args, err := splitDescriptorExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		switch name {
+		case DescriptorSh, DescriptorWsh:
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%s expects exactly one argument", name)
+			}
+			d.wrappers = append(d.wrappers, name)
+			expr = args[0]
+			continue
+		case DescriptorPkh, DescriptorWpkh, DescriptorTr:
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%s expects exactly one argument, script trees are not supported", name)
+			}
+
+			key, err := parseDescriptorKey(args[0], name == DescriptorTr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse key expression in %s: %w", name, err)
+			}
+			d.keys = append(d.keys, key)
+		case DescriptorMulti, DescriptorSortedMulti:
+			if len(args) < 2 {
+				return nil, fmt.Errorf("%s expects threshold and at least one key", name)
+			}
+
+			if _, err := fmt.Sscanf(args[0], "%d", &d.threshold); err != nil {
+				return nil, fmt.Errorf("invalid %s threshold %s: %w", name, args[0], err)
+			}
+
+			for _, arg := range args[1:] {
+				key, err := parseDescriptorKey(arg, false)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse key expression in %s: %w", name, err)
+				}
+				d.keys = append(d.keys, key)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported descriptor function: %s", name)
+		}
+
+		d.script = name
+		break
+	}
+
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// validate checks nesting of script functions and network of keys
+func (d *Descriptor) validate() error {
+	wrappers := strings.Join(d.wrappers, "/")
+	switch d.script {
+	case DescriptorPkh, DescriptorTr:
+		if len(wrappers) > 0 {
+			return fmt.Errorf("%s cannot be nested", d.script)
+		}
+	case DescriptorWpkh:
+		if len(wrappers) > 0 && wrappers != DescriptorSh {
+			return fmt.Errorf("%s can only be nested in %s", d.script, DescriptorSh)
+		}
+	case DescriptorMulti, DescriptorSortedMulti:
+		switch wrappers {
+		case DescriptorSh, DescriptorWsh, path.Join(DescriptorSh, DescriptorWsh):
+		default:
+			return fmt.Errorf("%s must be nested in %s, %s or %s(%s)", d.script,
+				DescriptorSh, DescriptorWsh, DescriptorSh, DescriptorWsh)
+		}
+	}
+
+	for _, key := range d.keys {
+		if key.xKey == nil {
+			continue
+		}
+
+		network, err := networkOf(key.xKey)
+		if err != nil {
+			return err
+		}
+
+		if len(d.network) > 0 && d.network != network {
+			return fmt.Errorf("descriptor keys belong to different networks")
+		}
+		d.network = network
+	}
+
+	if len(d.network) == 0 {
+		d.network = NetworkTypeMainnet
+	}
+
+	return nil
+}
+
+// String returns descriptor with checksum
+func (d *Descriptor) String() string {
+	desc, err := addDescriptorChecksum(d.desc)
+	if err != nil {
+		return d.desc
+	}
+
+	return desc
+}
+
+// IsRange returns true if the descriptor contains wildcard keys
+func (d *Descriptor) IsRange() bool {
+	for _, key := range d.keys {
+		if key.wildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Derive returns the address for the wildcard index. Index is ignored
+// if descriptor is not ranged
+func (d *Descriptor) Derive(index uint32) (*Key, error) {
+	pubKeys := make([][]byte, len(d.keys))
+	for i, key := range d.keys {
+		pubKey, err := key.derive(index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key %d: %w", i, err)
+		}
+		pubKeys[i] = pubKey
+	}
+
+	params := netParams[d.network]
+	key := &Key{
+		Network:  d.network,
+		CoinType: CoinTypeBtc,
+	}
+
+	if len(d.keys) == 1 && d.script != DescriptorMulti && d.script != DescriptorSortedMulti {
+		key.PubKeyHex = hex.EncodeToString(pubKeys[0])
+		key.DerivationPath = d.keys[0].derivationPath(index)
+	}
+
+	switch d.script {
+	case DescriptorPkh:
+		addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubKeys[0]), params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate new address pub key hash: %w", err)
+		}
+		key.Addr = addr.EncodeAddress()
+		key.AddrType = AddrTypeLegacy
+	case DescriptorWpkh:
+		addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pubKeys[0]), params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate new address witness pub key hash: %w", err)
+		}
+		key.Addr = addr.EncodeAddress()
+		key.AddrType = fmt.Sprintf("%s, %s", AddrTypeSegWitNative, AddrTypeBech32)
+
+		if len(d.wrappers) > 0 {
+			redeemScript, err := txscript.PayToAddrScript(addr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate pay to addr script: %w", err)
+			}
+
+			addressScriptHash, err := btcutil.NewAddressScriptHash(redeemScript, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate new address script hash: %w", err)
+			}
+			key.Addr = addressScriptHash.EncodeAddress()
+			key.AddrType = fmt.Sprintf("%s, %s", AddrTypeSegWitCompatible, AddrTypeP2sh)
+		}
+	case DescriptorTr:
+		internalKey, outputKey, err := taprootOutputKey(pubKeys[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to tweak taproot internal key: %w", err)
+		}
+
+		if key.Addr, err = taprootAddr(outputKey, params); err != nil {
+			return nil, err
+		}
+		key.AddrType = fmt.Sprintf("%s, %s", AddrTypeTaproot, AddrTypeP2tr)
+		key.InternalKey = hex.EncodeToString(internalKey)
+		key.OutputKey = hex.EncodeToString(outputKey)
+	case DescriptorMulti, DescriptorSortedMulti:
+		script, err := multisigScript(d.threshold, pubKeys, d.script == DescriptorSortedMulti)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate multisig script: %w", err)
+		}
+
+		switch strings.Join(d.wrappers, "/") {
+		case DescriptorSh:
+			addr, err := btcutil.NewAddressScriptHash(script, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate new address script hash: %w", err)
+			}
+			key.Addr = addr.EncodeAddress()
+			key.AddrType = AddrTypeP2sh
+		case DescriptorWsh:
+			if key.Addr, _, err = witnessScriptHashAddrs(script, params); err != nil {
+				return nil, err
+			}
+			key.AddrType = AddrTypeP2wsh
+			key.WitnessScript = hex.EncodeToString(script)
+		default:
+			if _, key.Addr, err = witnessScriptHashAddrs(script, params); err != nil {
+				return nil, err
+			}
+			key.AddrType = AddrTypeP2wshP2sh
+			key.WitnessScript = hex.EncodeToString(script)
+		}
+	}
+
+	desc := d.desc
+	if d.IsRange() {
+		desc = strings.NewReplacer(
+			"*'", fmt.Sprintf("%d'", index),
+			"*h", fmt.Sprintf("%dh", index),
+			"*", fmt.Sprintf("%d", index),
+		).Replace(desc)
+	}
+
+	desc, err := addDescriptorChecksum(desc)
+	if err != nil {
+		return nil, err
+	}
+	key.Descriptor = desc
+
+	return key, nil
+}
+
+// derive returns compressed public key for the wildcard index
+func (k *descriptorKey) derive(index uint32) ([]byte, error) {
+	if k.xKey == nil {
+		return k.pubKey, nil
+	}
+
+	indices := append([]uint32{}, k.indices...)
+	if k.wildcard {
+		if index >= bip32.FirstHardenedChild {
+			return nil, fmt.Errorf("index %d out of range", index)
+		}
+
+		if k.hardenedWildcard {
+			index += bip32.FirstHardenedChild
+		}
+		indices = append(indices, index)
+	}
+
+	xKey, err := deriveChildKeys(k.xKey, indices)
+	if err != nil {
+		return nil, err
+	}
+
+	if xKey.IsPrivate {
+		if xKey, err = publicKeyOf(xKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return xKey.Key, nil
+}
+
+// derivationPath returns full derivation path including key origin
+func (k *descriptorKey) derivationPath(index uint32) string {
+	if k.xKey == nil {
+		if len(k.origin) == 0 {
+			return ""
+		}
+		return FormatDerivationPath(k.origin)
+	}
+
+	indices := append(append([]uint32{}, k.origin...), k.indices...)
+	if k.wildcard {
+		if k.hardenedWildcard {
+			index += bip32.FirstHardenedChild
+		}
+		indices = append(indices, index)
+	}
+
+	return FormatDerivationPath(indices)
+}
+
+// splitDescriptorExpr splits expression such as name(arg1,arg2) into
+// function name and top level arguments
+func splitDescriptorExpr(expr string) (string, []string, error) {
+	open := strings.IndexByte(expr, '(')
+	if open < 0 || !strings.HasSuffix(expr, ")") {
+		return "", nil, fmt.Errorf("invalid descriptor expression: %s", expr)
+	}
+
+	name, inner := expr[:open], expr[open+1:len(expr)-1]
+
+	var args []string
+	depth, start := 0, 0
+	for i, c := range inner {
+		switch c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth < 0 {
+				return "", nil, fmt.Errorf("unbalanced brackets in descriptor expression: %s", expr)
+			}
+		case ',':
+			if depth == 0 {
+				args = append(args, inner[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if depth != 0 {
+		return "", nil, fmt.Errorf("unbalanced brackets in descriptor expression: %s", expr)
+	}
+
+	return name, append(args, inner[start:]), nil
+}
+
+// parseDescriptorKey parses key expression such as [d34db33f/84h/0h/0h]xpub.../0/*
+func parseDescriptorKey(expr string, xOnly bool) (*descriptorKey, error) {
+	key := &descriptorKey{}
+
+	if strings.HasPrefix(expr, "[") {
+		end := strings.IndexByte(expr, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("missing closing bracket for key origin: %s", expr)
+		}
+
+		parts := strings.Split(expr[1:end], "/")
+		if b, err := hex.DecodeString(parts[0]); err != nil || len(b) != 4 {
+			return nil, fmt.Errorf("invalid key origin fingerprint: %s", parts[0])
+		}
+		key.fingerprint = strings.ToLower(parts[0])
+
+		for _, part := range parts[1:] {
+			idx, err := parseChildIndex(strings.ToLower(part))
+			if err != nil {
+				return nil, fmt.Errorf("invalid key origin path %s: %w", expr[1:end], err)
+			}
+			key.origin = append(key.origin, idx)
+		}
+
+		expr = expr[end+1:]
+	}
+
+	parts := strings.Split(expr, "/")
+
+	if b, err := hex.DecodeString(parts[0]); err == nil {
+		if len(parts) > 1 {
+			return nil, fmt.Errorf("derivation path is not allowed after hex pub key: %s", expr)
+		}
+
+		if xOnly && len(b) == 32 {
+			b = append([]byte{0x02}, b...)
+		}
+
+		pub, err := btcec.ParsePubKey(b, btcec.S256())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pub key: %w", err)
+		}
+
+		if !bytes.Equal(pub.SerializeCompressed(), b) {
+			return nil, fmt.Errorf("only compressed pub keys are supported: %s", parts[0])
+		}
+
+		key.pubKey = b
+		return key, nil
+	}
+
+	xKey, err := bip32.B58Deserialize(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize key %s: %w", parts[0], err)
+	}
+
+	if _, ok := versionToVersions[hex.EncodeToString(xKey.Version)]; !ok {
+		return nil, fmt.Errorf("unknown key version found: %s", parts[0])
+	}
+	key.xKey = xKey
+
+	for i, part := range parts[1:] {
+		part = strings.ToLower(part)
+		if i == len(parts)-2 {
+			switch part {
+			case "*":
+				key.wildcard = true
+				continue
+			case "*h", "*'":
+				key.wildcard, key.hardenedWildcard = true, true
+				continue
+			}
+		}
+
+		idx, err := parseChildIndex(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key derivation path %s: %w", expr, err)
+		}
+		key.indices = append(key.indices, idx)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,167 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/kubetrail/bip39/pkg/seeds"
+	"github.com/tyler-smith/go-bip32"
+)
+
+// TestDescriptorChecksum uses examples from
+// https://github.com/bitcoin/bips/blob/master/bip-0380.mediawiki#test-vectors
+func TestDescriptorChecksum(t *testing.T) {
+	tests := map[string]string{
+		"raw(deadbeef)": "89f8spxm",
+		"pkh([d34db33f/44'/0'/0']xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL/1/*)": "ml40v0wf",
+	}
+
+	for desc, expected := range tests {
+		checksum, err := DescriptorChecksum(desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if checksum != expected {
+			t.Fatal("expected", expected, ", got", checksum, ", for", desc)
+		}
+	}
+
+	if _, err := ParseDescriptor("wpkh(02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9)#00000000"); err == nil {
+		t.Fatal("expected checksum error")
+	}
+}
+
+// TestNew_Descriptor checks that descriptors generated for keys
+// can be parsed back to derive the same addresses
+func TestNew_Descriptor(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	tests := []struct {
+		addrType   string
+		descriptor string
+	}{
+		{
+			addrType:   AddrTypeBip84,
+			descriptor: "wpkh([73c5da0a/84h/0h/0h]xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V/0/*)#afwvtk2s",
+		},
+		{
+			addrType: AddrTypeBip44,
+		},
+		{
+			addrType: AddrTypeBip49,
+		},
+		{
+			addrType: AddrTypeBip86,
+		},
+	}
+
+	for _, test := range tests {
+		for _, derivationPath := range []string{"auto", "m/0h/7"} {
+			key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: derivationPath, AddrType: test.addrType})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if derivationPath == "auto" && len(test.descriptor) > 0 && key.Descriptor != test.descriptor {
+				t.Fatal("expected", test.descriptor, ", got", key.Descriptor)
+			}
+
+			desc, err := ParseDescriptor(key.Descriptor)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			index := uint32(0)
+			if derivationPath != "auto" {
+				index = 7
+			}
+
+			derived, err := desc.Derive(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if derived.Addr != key.Addr {
+				t.Fatal("expected", key.Addr, ", got", derived.Addr, ", for", key.Descriptor)
+			}
+
+			if derived.PubKeyHex != key.PubKeyHex {
+				t.Fatal("expected", key.PubKeyHex, ", got", derived.PubKeyHex, ", for", key.Descriptor)
+			}
+		}
+	}
+}
+
+// TestDerive_KeyOrigin checks key origins of keys derived from non-root
+// extended keys, which start at the parent key of the input key
+func TestDerive_KeyOrigin(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	newKey := func(derivationPath string) *Key {
+		key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: derivationPath, AddrType: AddrTypeBip84})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return key
+	}
+
+	purpose, coin, account := newKey("m/84h"), newKey("m/84h/0h"), newKey("m/84h/0h/0h")
+
+	// children of master key resolve to the full key origin
+	key, err := Derive(purpose.XPrv, "m/0h/0h/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := newKey("m/84h/0h/0h/0/0")
+	if key.MasterFingerprint != "73c5da0a" || key.KeyOrigin != expected.KeyOrigin || key.Descriptor != expected.Descriptor {
+		t.Fatal("expected", expected.KeyOrigin, expected.Descriptor, ", got", key.MasterFingerprint, key.KeyOrigin, key.Descriptor)
+	}
+
+	// deeper keys start key origin at the parent key
+	for _, xKey := range []string{account.XPrv, account.XPub} {
+		key, err := Derive(xKey, "m/0/3")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := "[" + coin.Fingerprint + "/0h/0/3]"; key.KeyOrigin != expected {
+			t.Fatal("expected", expected, ", got", key.KeyOrigin)
+		}
+
+		if len(key.MasterFingerprint) > 0 {
+			t.Fatal("expected no master fingerprint, got", key.MasterFingerprint)
+		}
+
+		desc, err := ParseDescriptor(key.Descriptor)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		derived, err := desc.Derive(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if derived.Addr != key.Addr {
+			t.Fatal("expected", key.Addr, ", got", derived.Addr, ", for", key.Descriptor)
+		}
+	}
+
+	// zero parent fingerprint does not identify the parent key
+	xKey, err := bip32.B58Deserialize(account.XPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xKey.FingerPrint = make([]byte, 4)
+
+	key, err = Derive(xKey.B58Serialize(), "m/0/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(key.KeyOrigin) > 0 || len(key.MasterFingerprint) > 0 {
+		t.Fatal("expected no key origin, got", key.KeyOrigin, key.MasterFingerprint)
+	}
+}
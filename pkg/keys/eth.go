@@ -0,0 +1,61 @@
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"golang.org/x/crypto/sha3"
+)
+
+// ethereumMainNetParams only identifies ethereum keys since addresses
+// and private keys are not encoded using bitcoin prefixes
+var ethereumMainNetParams = chaincfg.Params{
+	Name:       "ethereum-mainnet",
+	HDCoinType: 60,
+}
+
+// ethEncodeAddr returns EIP-55 checksum encoded ethereum address
+// for the serialized public key
+func ethEncodeAddr(pubKey []byte, addrType string, _ *chaincfg.Params) (string, error) {
+	if addrType != AddrTypeEth {
+		return "", fmt.Errorf("invalid or unsupported addr type for eth: %s", addrType)
+	}
+
+	pub, err := btcec.ParsePubKey(pubKey, btcec.S256())
+	if err != nil {
+		return "", fmt.Errorf("failed to parse pub key: %w", err)
+	}
+
+	// address is the last 20 bytes of keccak-256 hash of the
+	// uncompressed public key without its prefix byte
+	addr := keccak256(pub.SerializeUncompressed()[1:])[12:]
+
+	return eip55Checksum(hex.EncodeToString(addr)), nil
+}
+
+// eip55Checksum applies mixed case checksum to lowercase hex address per
+// https://github.com/ethereum/EIPs/blob/master/EIPS/eip-55.md
+func eip55Checksum(addr string) string {
+	addr = strings.ToLower(strings.TrimPrefix(addr, "0x"))
+	hash := hex.EncodeToString(keccak256([]byte(addr)))
+
+	b := []byte(addr)
+	for i, c := range b {
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(b)
+}
+
+// keccak256 returns legacy keccak-256 hash used by ethereum,
+// which differs from the standardized sha3-256
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
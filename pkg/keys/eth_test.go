@@ -0,0 +1,156 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+// TestEip55Checksum uses vectors from
+// https://github.com/ethereum/EIPs/blob/master/EIPS/eip-55.md
+func TestEip55Checksum(t *testing.T) {
+	addrs := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+		"0x52908400098527886E0F7030069857D2E4169EE7",
+		"0xde709f2102306220921060314715629080e2fb77",
+	}
+
+	for _, addr := range addrs {
+		if got := eip55Checksum(strings.ToLower(addr)); got != addr {
+			t.Fatal("expected", addr, ", got", got)
+		}
+	}
+}
+
+func TestNew_Eth(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	tests := []struct {
+		coin      string
+		addrType  string
+		addr      string
+		prvKeyHex string
+	}{
+		{
+			coin:      CoinTypeEth,
+			addrType:  AddrTypeP2pkhOrP2sh,
+			addr:      "0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+			prvKeyHex: "1ab42cc412b618bdea3a599e3c9bae199ebf030895b039e9db1e30dafb12b727",
+		},
+		{
+			addrType:  AddrTypeEth,
+			addr:      "0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+			prvKeyHex: "1ab42cc412b618bdea3a599e3c9bae199ebf030895b039e9db1e30dafb12b727",
+		},
+	}
+
+	for _, test := range tests {
+		key, err := New(&Config{
+			Seed:           seed,
+			Network:        NetworkTypeMainnet,
+			DerivationPath: "auto",
+			AddrType:       test.addrType,
+			Coin:           test.coin,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.DerivationPath != "m/44h/60h/0h/0/0" {
+			t.Fatal("expected m/44h/60h/0h/0/0, got", key.DerivationPath)
+		}
+
+		if key.Addr != test.addr || key.PrvKeyHex != test.prvKeyHex {
+			t.Fatal("expected", test.addr, test.prvKeyHex, ", got", key.Addr, key.PrvKeyHex)
+		}
+
+		if len(key.PrvKeyWif) > 0 || len(key.Descriptor) > 0 {
+			t.Fatal("expected no wif and descriptor for eth keys")
+		}
+
+		if !strings.HasPrefix(key.PubKeyHex, "04") || len(key.PubKeyHex) != 130 {
+			t.Fatal("expected uncompressed pub key, got", key.PubKeyHex)
+		}
+	}
+
+	var addrs []string
+	if err := NewRange(&Config{
+		Seed:           seed,
+		Network:        NetworkTypeMainnet,
+		DerivationPath: "m/44h/60h/0h/0/*",
+		AddrType:       AddrTypeEth,
+	}, 0, 2, func(key *Key) error {
+		addrs = append(addrs, key.Addr)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"; addrs[1] != expected {
+		t.Fatal("expected", expected, ", got", addrs[1])
+	}
+
+	if _, err := New(&Config{Seed: seed, Network: NetworkTypeTestnet, DerivationPath: "auto", AddrType: AddrTypeEth}); err == nil {
+		t.Fatal("expected error for eth on testnet")
+	}
+}
+
+// TestDeriveWithCoin_Eth checks that xpub, which resolves to btc when
+// detecting coin from the key version, derives eth addresses when the
+// coin is provided
+func TestDeriveWithCoin_Eth(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	account, err := New(&Config{
+		Seed:           seed,
+		Network:        NetworkTypeMainnet,
+		DerivationPath: "m/44h/60h/0h",
+		AddrType:       AddrTypeP2pkhOrP2sh,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(account.XPub, "xpub") {
+		t.Fatal("expected xpub, got", account.XPub)
+	}
+
+	key, err := DeriveWithCoin(account.XPub, "m/0/0", CoinTypeEth, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"; key.Addr != expected {
+		t.Fatal("expected", expected, ", got", key.Addr)
+	}
+
+	if key.CoinType != CoinTypeEth || key.AddrType != AddrTypeEth {
+		t.Fatal("expected eth coin and address type, got", key.CoinType, key.AddrType)
+	}
+
+	key, err = Derive(account.XPub, "m/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key.CoinType != CoinTypeBtc || strings.HasPrefix(key.Addr, "0x") {
+		t.Fatal("expected xpub to be detected as btc, got", key.CoinType, key.Addr)
+	}
+
+	decoded, err := DecodeExtendedKeyWithCoin(account.XPub, CoinTypeEth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.CoinType != CoinTypeEth || !strings.HasPrefix(decoded.Addr, "0x") {
+		t.Fatal("expected eth key, got", decoded.CoinType, decoded.Addr)
+	}
+
+	if _, err := DeriveWithCoin(account.XPub, "m/0/0", CoinTypeLtc, "", nil); err == nil {
+		t.Fatal("expected error for xpub as ltc key")
+	}
+}
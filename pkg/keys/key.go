@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -14,7 +15,6 @@ import (
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
-	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil"
 	"github.com/tyler-smith/go-bip32"
 )
@@ -32,15 +32,18 @@ func init() {
 var netParams = map[string]*chaincfg.Params{
 	NetworkTypeMainnet: &chaincfg.MainNetParams,
 	NetworkTypeTestnet: &chaincfg.TestNet3Params,
+	NetworkTypeSignet:  &chaincfg.SigNetParams,
+	NetworkTypeRegtest: &chaincfg.RegressionNetParams,
 }
 
-var (
-	keyVersions       map[string][]byte
-	mainnetVersions   map[string]struct{}
-	testnetVersions   map[string]struct{}
-	versionToVersions map[string][]string
-	versionToAddrType map[string]string
-)
+// networkTypes lists btc networks in the order of detection, such that
+// prefixes shared by testnet, signet and regtest resolve to testnet
+var networkTypes = []string{
+	NetworkTypeMainnet,
+	NetworkTypeTestnet,
+	NetworkTypeSignet,
+	NetworkTypeRegtest,
+}
 
 func mustDecodeHex(input string) []byte {
 	b, err := hex.DecodeString(input)
@@ -50,108 +53,6 @@ func mustDecodeHex(input string) []byte {
 	return b
 }
 
-func init() {
-	// https://electrum.readthedocs.io/en/latest/xpub_version_bytes.html#specification
-	keyVersions = map[string][]byte{
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2pkhOrP2sh, KeyTypePub): mustDecodeHex(xpub),
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2pkhOrP2sh, KeyTypePrv): mustDecodeHex(xprv),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2pkhOrP2sh, KeyTypePub): mustDecodeHex(tpub),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2pkhOrP2sh, KeyTypePrv): mustDecodeHex(tprv),
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2wpkhP2sh, KeyTypePub):  mustDecodeHex(ypub),
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2wpkhP2sh, KeyTypePrv):  mustDecodeHex(yprv),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2wpkhP2sh, KeyTypePub):  mustDecodeHex(upub),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2wpkhP2sh, KeyTypePrv):  mustDecodeHex(uprv),
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2wshP2sh, KeyTypePub):   mustDecodeHex(Ypub),
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2wshP2sh, KeyTypePrv):   mustDecodeHex(Yprv),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2wshP2sh, KeyTypePub):   mustDecodeHex(Upub),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2wshP2sh, KeyTypePrv):   mustDecodeHex(Uprv),
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2wpkh, KeyTypePub):      mustDecodeHex(zpub),
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2wpkh, KeyTypePrv):      mustDecodeHex(zprv),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2wpkh, KeyTypePub):      mustDecodeHex(vpub),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2wpkh, KeyTypePrv):      mustDecodeHex(vprv),
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2wsh, KeyTypePub):       mustDecodeHex(Zpub),
-		path.Join(CoinTypeBtc, NetworkTypeMainnet, AddrTypeP2wsh, KeyTypePrv):       mustDecodeHex(Zprv),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2wsh, KeyTypePub):       mustDecodeHex(Vpub),
-		path.Join(CoinTypeBtc, NetworkTypeTestnet, AddrTypeP2wsh, KeyTypePrv):       mustDecodeHex(Vprv),
-	}
-
-	mainnetVersions = map[string]struct{}{
-		xpub: {},
-		xprv: {},
-		ypub: {},
-		yprv: {},
-		Ypub: {},
-		Yprv: {},
-		zpub: {},
-		zprv: {},
-		Zpub: {},
-		Zprv: {},
-	}
-
-	testnetVersions = map[string]struct{}{
-		tpub: {},
-		tprv: {},
-		upub: {},
-		uprv: {},
-		Upub: {},
-		Uprv: {},
-		vpub: {},
-		vprv: {},
-		Vpub: {},
-		Vprv: {},
-	}
-
-	// versionToVersions is used to detect input extended key version
-	// and thereby assign bip32 pkg key versions, hence each detected
-	// key version, whether from private key or public key, is matched
-	// against a tuple of corresponding public and private key versions
-	versionToVersions = map[string][]string{
-		xpub: {xpub, xprv},
-		xprv: {xpub, xprv},
-		ypub: {ypub, yprv},
-		yprv: {ypub, yprv},
-		Ypub: {Ypub, Yprv},
-		Yprv: {Ypub, Yprv},
-		zpub: {zpub, zprv},
-		zprv: {zpub, zprv},
-		Zpub: {Zpub, Zprv},
-		Zprv: {Zpub, Zprv},
-		tpub: {tpub, tprv},
-		tprv: {tpub, tprv},
-		upub: {upub, uprv},
-		uprv: {upub, uprv},
-		Upub: {Upub, Uprv},
-		Uprv: {Upub, Uprv},
-		vpub: {vpub, vprv},
-		vprv: {vpub, vprv},
-		Vpub: {Vpub, Vprv},
-		Vprv: {Vpub, Vprv},
-	}
-
-	versionToAddrType = map[string]string{
-		xpub: AddrTypeP2pkhOrP2sh,
-		xprv: AddrTypeP2pkhOrP2sh,
-		ypub: AddrTypeP2wpkhP2sh,
-		yprv: AddrTypeP2wpkhP2sh,
-		Ypub: AddrTypeP2wshP2sh,
-		Yprv: AddrTypeP2wshP2sh,
-		zpub: AddrTypeP2wpkh,
-		zprv: AddrTypeP2wpkh,
-		Zpub: AddrTypeP2wsh,
-		Zprv: AddrTypeP2wsh,
-		tpub: AddrTypeP2pkhOrP2sh,
-		tprv: AddrTypeP2pkhOrP2sh,
-		upub: AddrTypeP2wpkhP2sh,
-		uprv: AddrTypeP2wpkhP2sh,
-		Upub: AddrTypeP2wshP2sh,
-		Uprv: AddrTypeP2wshP2sh,
-		vpub: AddrTypeP2wpkh,
-		vprv: AddrTypeP2wpkh,
-		Vpub: AddrTypeP2wsh,
-		Vprv: AddrTypeP2wsh,
-	}
-}
-
 // IsValidBase58String checks if all chars in input string
 // belong to valid base58 char set
 func IsValidBase58String(input string) bool {
@@ -171,18 +72,40 @@ func IsValidBase58String(input string) bool {
 // Key represents BIP32 key components that are presented
 // to the user
 type Key struct {
-	Seed           string `json:"seed,omitempty" yaml:"seed,omitempty"`
-	XPrv           string `json:"xPrv,omitempty" yaml:"xPrv,omitempty"`
-	XPub           string `json:"xPub,omitempty" yaml:"xPub,omitempty"`
-	PubKeyHex      string `json:"pubKeyHex,omitempty" yaml:"pubKeyHex,omitempty"`
-	PrvKeyWif      string `json:"prvKeyWif,omitempty" yaml:"prvKeyWif,omitempty"`
-	Addr           string `json:"addr,omitempty" yaml:"addr,omitempty"`
-	AddrType       string `json:"addrType,omitempty" yaml:"addrType,omitempty"`
-	DerivationPath string `json:"derivationPath,omitempty" yaml:"derivationPath,omitempty"`
-	CoinType       string `json:"coinType,omitempty" yaml:"coinType,omitempty"`
-	Network        string `json:"network,omitempty" yaml:"network,omitempty"`
-	segWitNested   string
-	segWitBech32   string
+	Seed              string `json:"seed,omitempty" yaml:"seed,omitempty"`
+	XPrv              string `json:"xPrv,omitempty" yaml:"xPrv,omitempty"`
+	XPub              string `json:"xPub,omitempty" yaml:"xPub,omitempty"`
+	PubKeyHex         string `json:"pubKeyHex,omitempty" yaml:"pubKeyHex,omitempty"`
+	Uncompressed      bool   `json:"uncompressed,omitempty" yaml:"uncompressed,omitempty"`
+	PrvKeyWif         string `json:"prvKeyWif,omitempty" yaml:"prvKeyWif,omitempty"`
+	PrvKeyHex         string `json:"prvKeyHex,omitempty" yaml:"prvKeyHex,omitempty"`
+	Addr              string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	LegacyAddr        string `json:"legacyAddr,omitempty" yaml:"legacyAddr,omitempty"`
+	Addrs             *Addrs `json:"addrs,omitempty" yaml:"addrs,omitempty"`
+	AddrType          string `json:"addrType,omitempty" yaml:"addrType,omitempty"`
+	DerivationPath    string `json:"derivationPath,omitempty" yaml:"derivationPath,omitempty"`
+	MasterFingerprint string `json:"masterFingerprint,omitempty" yaml:"masterFingerprint,omitempty"`
+	KeyOrigin         string `json:"keyOrigin,omitempty" yaml:"keyOrigin,omitempty"`
+	CoinType          string `json:"coinType,omitempty" yaml:"coinType,omitempty"`
+	Network           string `json:"network,omitempty" yaml:"network,omitempty"`
+	WitnessScript     string `json:"witnessScript,omitempty" yaml:"witnessScript,omitempty"`
+	InternalKey       string `json:"internalKey,omitempty" yaml:"internalKey,omitempty"`
+	OutputKey         string `json:"outputKey,omitempty" yaml:"outputKey,omitempty"`
+	Descriptor        string `json:"descriptor,omitempty" yaml:"descriptor,omitempty"`
+	KeyMetadata       `yaml:",inline"`
+	segWitNested      string
+	segWitBech32      string
+}
+
+// KeyMetadata represents extended key serialization details such
+// as the depth and the parent fingerprint. Child index is formatted
+// with h suffix when hardened
+type KeyMetadata struct {
+	Depth             *uint8 `json:"depth,omitempty" yaml:"depth,omitempty"`
+	ParentFingerprint string `json:"parentFingerprint,omitempty" yaml:"parentFingerprint,omitempty"`
+	ChildIndex        string `json:"childIndex,omitempty" yaml:"childIndex,omitempty"`
+	ChainCode         string `json:"chainCode,omitempty" yaml:"chainCode,omitempty"`
+	Fingerprint       string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
 }
 
 type Config struct {
@@ -190,112 +113,434 @@ type Config struct {
 	Network        string
 	DerivationPath string
 	AddrType       string
+	// Coin is the name of a registered coin, defaults to btc
+	Coin string
+	// Script is used for p2wsh and p2wsh-p2sh address types
+	Script *Script
 }
 
 // New generates a new key pair with a seed. The derivation paths
 // can be successive derivation indices such as m, 0, 0h etc.
 // or can be provided as m/0/0h.
 func New(config *Config) (*Key, error) {
+	g, derivationPath, err := newKeyGenerator(config)
+	if err != nil {
+		return nil, err
+	}
+
+	xKey, err := extendedKeyToDerivedExtendedKey(g.rootKey, derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive extended key: %w", err)
+	}
+
+	indices, err := ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse derivation path: %w", err)
+	}
+
+	return g.key(xKey, indices)
+}
+
+// NewRange generates keys similar to New, however, the derivation path can
+// contain range elements such as 0-999, {0,1} or a wildcard *, which expands
+// to count indices beginning with startIndex. Parent keys are derived only
+// once and fn is called for each generated key in order
+func NewRange(config *Config, startIndex, count uint32, fn func(key *Key) error) error {
+	g, derivationPath, err := newKeyGenerator(config)
+	if err != nil {
+		return err
+	}
+
+	elements, err := parseDerivationPathRange(derivationPath, startIndex, count)
+	if err != nil {
+		return fmt.Errorf("failed to parse derivation path: %w", err)
+	}
+
+	if g.script.isExplicit() && !isSingleIndex(elements) {
+		return fmt.Errorf("explicit witness script describes a single address and cannot be used for a range of keys")
+	}
+
+	return g.walk(g.rootKey, elements, nil, fn)
+}
+
+// IsRangeDerivationPath returns true if the derivation path contains
+// range elements such as 0-999, {0,1} or a wildcard *
+func IsRangeDerivationPath(derivationPath string) bool {
+	return strings.ContainsAny(derivationPath, "*{},-")
+}
+
+// keyGenerator holds state shared by the keys generated
+// from the same root key
+type keyGenerator struct {
+	rootKey     *bip32.Key
+	seed        []byte
+	coin        *Coin
+	network     string
+	addrType    string
+	script      *Script
+	descriptors map[string]string
+	// origin is the fingerprint and the path of the root key relative
+	// to the key where derivation starts for key origins, i.e., the
+	// root key itself when it is a master key or the parent of the
+	// root key otherwise. Fingerprint is empty when not known
+	originFingerprint []byte
+	originPath        []uint32
+}
+
+// setOrigin sets key origin of the root key using its depth and
+// parent fingerprint. Origin of non-master keys is not known when
+// parent fingerprint is zero
+func (g *keyGenerator) setOrigin() error {
+	if g.rootKey.Depth == 0 {
+		fingerprint, err := fingerprintOf(g.rootKey)
+		if err != nil {
+			return fmt.Errorf("failed to get master key fingerprint: %w", err)
+		}
+
+		g.originFingerprint, g.originPath = fingerprint, nil
+		return nil
+	}
+
+	if len(g.rootKey.FingerPrint) != 4 || bytes.Equal(g.rootKey.FingerPrint, make([]byte, 4)) ||
+		len(g.rootKey.ChildNumber) != 4 {
+		return nil
+	}
+
+	g.originFingerprint = g.rootKey.FingerPrint
+	g.originPath = []uint32{binary.BigEndian.Uint32(g.rootKey.ChildNumber)}
+
+	return nil
+}
+
+// keyOrigin formats key origin of the key derived from the root key
+// along the indices, which is empty when origin is not known
+func (g *keyGenerator) keyOrigin(indices []uint32) string {
+	if len(g.originFingerprint) == 0 {
+		return ""
+	}
+
+	originPath := append(append([]uint32{}, g.originPath...), indices...)
+
+	return fmt.Sprintf("[%x%s]", g.originFingerprint,
+		strings.TrimPrefix(FormatDerivationPath(originPath), "m"))
+}
+
+// newKeyGenerator prepares master key from the seed and returns
+// it along with derivation path after resolving auto value
+func newKeyGenerator(config *Config) (*keyGenerator, string, error) {
 	seed, network, derivationPath, addrType :=
 		config.Seed,
 		strings.ToLower(config.Network),
 		strings.ToLower(config.DerivationPath),
 		strings.ToLower(config.AddrType)
 
-	switch network {
-	case NetworkTypeMainnet, NetworkTypeTestnet:
-	default:
-		return nil, fmt.Errorf("invalid or unsupported network: %s. allowed networks are %v", network,
-			[]string{NetworkTypeMainnet, NetworkTypeTestnet},
-		)
-	}
-
 	// when using BIP-32 address type, the default behavior of the
 	// derivation path is simply m/0/0
 	if derivationPath == "auto" && addrType == AddrTypeBip32 {
 		derivationPath = "m/0/0"
 	}
 
-	switch addrType {
-	case AddrTypeLegacy, AddrTypeBip44, AddrTypeBip32:
-		addrType = AddrTypeP2pkhOrP2sh
-	case AddrTypeP2sh, AddrTypeSegWitCompatible, AddrTypeBip49:
-		addrType = AddrTypeP2wpkhP2sh
-	case AddrTypeSegWitNative, AddrTypeBech32, AddrTypeBip84:
-		addrType = AddrTypeP2wpkh
+	addrType = normalizeAddrType(addrType)
+
+	// eth address type implies eth coin
+	coinType := strings.ToLower(config.Coin)
+	if addrType == AddrTypeEth && (coinType == "" || coinType == CoinTypeBtc) {
+		coinType = CoinTypeEth
+	}
+
+	coin, err := CoinByName(coinType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	coinNetwork, err := coin.network(network)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// coins with a single address type, such as eth, use it
+	// in place of the default address type
+	if versions := coinNetwork.Versions; len(versions) == 1 && addrType == AddrTypeP2pkhOrP2sh {
+		addrType = versions[0].AddrType
 	}
 
-	// coin type is 0h for BTC mainnet and
-	// 1h for BTC testnet per
+	// coin type is per coin on mainnet, such as 0h for BTC and 2h for LTC,
+	// and 1h on test networks for all coins per
 	// https://github.com/satoshilabs/slips/blob/master/slip-0044.md
 	if derivationPath == "auto" {
-		switch network {
-		case NetworkTypeMainnet:
-			switch addrType {
-			case AddrTypeP2pkhOrP2sh:
-				derivationPath = "m/44h/0h/0h/0/0"
-			case AddrTypeP2wpkhP2sh, AddrTypeP2wshP2sh:
-				derivationPath = "m/49h/0h/0h/0/0"
-			case AddrTypeP2wpkh, AddrTypeP2wsh:
-				derivationPath = "m/84h/0h/0h/0/0"
-			}
-		case NetworkTypeTestnet:
-			switch addrType {
-			case AddrTypeP2pkhOrP2sh:
-				derivationPath = "m/44h/1h/0h/0/0"
-			case AddrTypeP2wpkhP2sh, AddrTypeP2wshP2sh:
-				derivationPath = "m/49h/1h/0h/0/0"
-			case AddrTypeP2wpkh, AddrTypeP2wsh:
-				derivationPath = "m/84h/1h/0h/0/0"
-			}
+		var purpose uint32
+		switch addrType {
+		case AddrTypeP2pkhOrP2sh, AddrTypeEth:
+			purpose = 44
+		case AddrTypeP2wpkhP2sh, AddrTypeP2wshP2sh:
+			purpose = 49
+		case AddrTypeP2wpkh, AddrTypeP2wsh:
+			purpose = 84
+		case AddrTypeP2tr:
+			purpose = 86
+		}
+
+		if purpose > 0 {
+			derivationPath = fmt.Sprintf("m/%dh/%dh/0h/0/0", purpose, coin.coinIndex(network))
 		}
 	}
 
-	// setup key versions based on network
-	var ok bool
-	bip32.PublicWalletVersion, ok = keyVersions[path.Join(CoinTypeBtc, network, addrType, KeyTypePub)]
-	if !ok {
-		return nil, fmt.Errorf("failed to get key version for pubic key")
+	// key versions are assigned on the key itself instead of mutating
+	// bip32 pkg globals so that concurrent calls do not interfere
+	if _, ok := keyVersions[path.Join(coin.Name, network, addrType, KeyTypePub)]; !ok {
+		return nil, "", fmt.Errorf("addr type %s is not supported for %s %s", addrType, coin.Name, network)
 	}
 
-	bip32.PrivateWalletVersion, ok = keyVersions[path.Join(CoinTypeBtc, network, addrType, KeyTypePrv)]
+	prvVersion, ok := keyVersions[path.Join(coin.Name, network, addrType, KeyTypePrv)]
 	if !ok {
-		return nil, fmt.Errorf("failed to get key version for private key")
+		return nil, "", fmt.Errorf("failed to get key version for private key")
 	}
 
-	xKey, err := bip32.NewMasterKey(seed)
+	rootKey, err := bip32.NewMasterKey(seed)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate root key: %w", err)
+		return nil, "", fmt.Errorf("failed to generate root key: %w", err)
+	}
+	rootKey.Version = prvVersion
+
+	g := &keyGenerator{
+		rootKey:     rootKey,
+		seed:        seed,
+		coin:        coin,
+		network:     network,
+		addrType:    addrType,
+		script:      config.Script,
+		descriptors: make(map[string]string),
 	}
 
-	xKey, err = extendedKeyToDerivedExtendedKey(xKey, derivationPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to derive extended key: %w", err)
+	if err := g.setOrigin(); err != nil {
+		return nil, "", err
+	}
+
+	return g, derivationPath, nil
+}
+
+// normalizeAddrType maps address type aliases to their
+// corresponding canonical address types
+func normalizeAddrType(addrType string) string {
+	switch strings.ToLower(addrType) {
+	case AddrTypeLegacy, AddrTypeBip44, AddrTypeBip32:
+		return AddrTypeP2pkhOrP2sh
+	case AddrTypeP2sh, AddrTypeSegWitCompatible, AddrTypeBip49:
+		return AddrTypeP2wpkhP2sh
+	case AddrTypeSegWitNative, AddrTypeBech32, AddrTypeBip84:
+		return AddrTypeP2wpkh
+	case AddrTypeTaproot, AddrTypeBip86:
+		return AddrTypeP2tr
+	default:
+		return strings.ToLower(addrType)
 	}
+}
 
-	key, err := extendedKeyToKey(xKey)
+// key converts the extended key derived from the root key along
+// the indices to Key for output
+func (g *keyGenerator) key(xKey *bip32.Key, indices []uint32) (*Key, error) {
+	key, err := extendedKeyToKey(xKey, g.coin, g.network)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert extended key for output: %w", err)
 	}
 
-	key.Seed = hex.EncodeToString(seed)
-	key.DerivationPath = derivationPath
+	if err := setAddr(key, g.addrType, g.script, indices); err != nil {
+		return nil, err
+	}
+
+	key.DerivationPath = FormatDerivationPath(indices)
+
+	if g.seed != nil {
+		key.Seed = hex.EncodeToString(g.seed)
+	}
+
+	// origin fingerprint is the master key fingerprint for
+	// master keys and children of master keys
+	key.KeyOrigin = g.keyOrigin(indices)
+	if len(g.originFingerprint) > 0 && g.rootKey.Depth <= 1 {
+		key.MasterFingerprint = hex.EncodeToString(g.originFingerprint)
+	}
+
+	// descriptors only describe btc keys
+	if g.coin.Name != CoinTypeBtc {
+		return key, nil
+	}
+
+	// sibling keys share the same ranged descriptor
+	descriptorID := key.DerivationPath
+	if n := len(indices); n > 0 && indices[n-1] < bip32.FirstHardenedChild {
+		descriptorID = FormatDerivationPath(indices[:n-1])
+	}
 
+	descriptor, ok := g.descriptors[descriptorID]
+	if !ok {
+		descriptor, err = descriptorFor(g, indices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate output descriptor: %w", err)
+		}
+		g.descriptors[descriptorID] = descriptor
+	}
+	key.Descriptor = descriptor
+
+	return key, nil
+}
+
+// isSingleIndex returns true if the range elements
+// describe a single derivation path
+func isSingleIndex(elements [][]indexSpan) bool {
+	for _, element := range elements {
+		if len(element) != 1 || element[0].first != element[0].last {
+			return false
+		}
+	}
+
+	return true
+}
+
+// walk derives keys for each combination of indices in the range
+// elements deriving each parent key only once
+func (g *keyGenerator) walk(xKey *bip32.Key, elements [][]indexSpan, indices []uint32, fn func(key *Key) error) error {
+	if len(elements) == 0 {
+		key, err := g.key(xKey, indices)
+		if err != nil {
+			return err
+		}
+
+		return fn(key)
+	}
+
+	// spans are iterated lazily so that large spans
+	// stream keys without allocating all indices
+	for _, span := range elements[0] {
+		for idx := span.first; ; idx++ {
+			childKey, err := deriveChildKeys(xKey, []uint32{idx})
+			if err != nil {
+				return fmt.Errorf("failed to derive extended key: %w", err)
+			}
+
+			childIndices := append(indices[:len(indices):len(indices)], idx)
+			if err := g.walk(childKey, elements[1:], childIndices, fn); err != nil {
+				return err
+			}
+
+			if idx == span.last {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// setAddr assigns address and address type to the key per address type.
+// Indices are the derivation path of the key used for deriving cosigner
+// extended keys in the witness script
+func setAddr(key *Key, addrType string, script *Script, indices []uint32) error {
 	switch addrType {
 	case AddrTypeP2pkhOrP2sh:
 		key.segWitNested, key.segWitBech32 = "", ""
 		key.AddrType = AddrTypeLegacy
-	case AddrTypeP2wpkhP2sh, AddrTypeP2wshP2sh:
+	case AddrTypeP2wpkhP2sh:
 		key.Addr, key.segWitNested, key.segWitBech32 = key.segWitNested, "", ""
 		key.AddrType = fmt.Sprintf("%s, %s", AddrTypeSegWitCompatible, AddrTypeP2sh)
-	case AddrTypeP2wpkh, AddrTypeP2wsh:
+	case AddrTypeP2wpkh:
 		key.Addr, key.segWitNested, key.segWitBech32 = key.segWitBech32, "", ""
 		key.AddrType = fmt.Sprintf("%s, %s", AddrTypeSegWitNative, AddrTypeBech32)
+	case AddrTypeP2wshP2sh, AddrTypeP2wsh:
+		key.segWitNested, key.segWitBech32 = "", ""
+		key.AddrType = addrType
+		if err := setWitnessScriptAddr(key, addrType, script, indices); err != nil {
+			return fmt.Errorf("failed to generate witness script address: %w", err)
+		}
+	case AddrTypeEth:
+		key.segWitNested, key.segWitBech32 = "", ""
+		key.AddrType = AddrTypeEth
+	case AddrTypeP2tr:
+		key.segWitNested, key.segWitBech32 = "", ""
+		key.AddrType = fmt.Sprintf("%s, %s", AddrTypeTaproot, AddrTypeP2tr)
+		if err := setTaprootAddr(key); err != nil {
+			return fmt.Errorf("failed to generate taproot address: %w", err)
+		}
 	default:
-		return nil, fmt.Errorf("invalid addr type")
+		return fmt.Errorf("invalid addr type")
 	}
 
-	return key, nil
+	return nil
+}
+
+// setTaprootAddr assigns single key p2tr address to the key
+// along with taproot internal and output keys
+func setTaprootAddr(key *Key) error {
+	pubKey, err := hex.DecodeString(key.PubKeyHex)
+	if err != nil {
+		return fmt.Errorf("failed to decode pub key: %w", err)
+	}
+
+	internalKey, outputKey, err := taprootOutputKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("failed to tweak taproot internal key: %w", err)
+	}
+
+	params, err := coinParams(key.CoinType, key.Network)
+	if err != nil {
+		return err
+	}
+
+	addr, err := taprootAddr(outputKey, params)
+	if err != nil {
+		return fmt.Errorf("failed to generate taproot address: %w", err)
+	}
+
+	key.Addr = addr
+	key.InternalKey = hex.EncodeToString(internalKey)
+	key.OutputKey = hex.EncodeToString(outputKey)
+
+	return nil
+}
+
+// setWitnessScriptAddr assigns p2wsh or p2wsh-p2sh address to the key
+// using the witness script. Address is left empty when no script is
+// provided since a single key address would be misleading for these
+// address types. Cosigner extended keys are derived along the
+// non-hardened suffix of the indices
+func setWitnessScriptAddr(key *Key, addrType string, script *Script, indices []uint32) error {
+	key.Addr = ""
+	if script == nil {
+		return nil
+	}
+
+	pubKey, err := hex.DecodeString(key.PubKeyHex)
+	if err != nil {
+		return fmt.Errorf("failed to decode pub key: %w", err)
+	}
+
+	witnessScript, err := script.witnessScript(pubKey, indices[hardenedLen(indices):])
+	if err != nil {
+		return fmt.Errorf("failed to get witness script: %w", err)
+	}
+
+	params, err := coinParams(key.CoinType, key.Network)
+	if err != nil {
+		return err
+	}
+
+	p2wsh, p2wshP2sh, err := witnessScriptHashAddrs(witnessScript, params)
+	if err != nil {
+		return fmt.Errorf("failed to generate witness script hash addresses: %w", err)
+	}
+
+	switch addrType {
+	case AddrTypeP2wsh:
+		key.Addr = p2wsh
+	case AddrTypeP2wshP2sh:
+		key.Addr = p2wshP2sh
+	default:
+		return fmt.Errorf("invalid addr type for witness script: %s", addrType)
+	}
+
+	key.WitnessScript = hex.EncodeToString(witnessScript)
+
+	return nil
 }
 
 func Prompt(w io.Writer) error {
@@ -317,7 +562,31 @@ func Read(r io.Reader) (string, error) {
 	return key, nil
 }
 
+// Addrs lists single key addresses of a public key. Segwit addresses
+// are not generated for uncompressed public keys
+type Addrs struct {
+	P2pkh      string `json:"p2pkh,omitempty" yaml:"p2pkh,omitempty"`
+	P2wpkhP2sh string `json:"p2wpkhP2sh,omitempty" yaml:"p2wpkhP2sh,omitempty"`
+	P2wpkh     string `json:"p2wpkh,omitempty" yaml:"p2wpkh,omitempty"`
+	P2tr       string `json:"p2tr,omitempty" yaml:"p2tr,omitempty"`
+}
+
+// DecodePublicHex decodes hex encoded public key on mainnet
 func DecodePublicHex(keyString string) (*Key, error) {
+	return DecodePublicHexWithNetwork(keyString, NetworkTypeMainnet, AddrTypeP2pkhOrP2sh)
+}
+
+// DecodePublicHexWithNetwork decodes hex encoded public key, compressed or
+// uncompressed, and generates its single key addresses on the network.
+// Addr is set per the addr type, which needs to be p2pkh for uncompressed
+// public keys since segwit requires compressed keys
+func DecodePublicHexWithNetwork(keyString, network, addrType string) (*Key, error) {
+	network = strings.ToLower(network)
+	params, ok := netParams[network]
+	if !ok {
+		return nil, fmt.Errorf("invalid or unsupported network: %s", network)
+	}
+
 	pubKeyBytes, err := hex.DecodeString(keyString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode pub key: %w", err)
@@ -328,21 +597,56 @@ func DecodePublicHex(keyString string) (*Key, error) {
 		return nil, fmt.Errorf("failed to parse pub key: %w", err)
 	}
 
-	addressPubKey, err := btcutil.NewAddressPubKey(pub.SerializeCompressed(), netParams[NetworkTypeMainnet])
+	uncompressed := len(pubKeyBytes) == btcec.PubKeyBytesLenUncompressed
+	serializedPubKey := pub.SerializeCompressed()
+	if uncompressed {
+		serializedPubKey = pub.SerializeUncompressed()
+	}
+
+	addrType = normalizeAddrType(addrType)
+	switch addrType {
+	case AddrTypeP2pkhOrP2sh:
+	case AddrTypeP2wpkhP2sh, AddrTypeP2wpkh, AddrTypeP2tr:
+		if uncompressed {
+			return nil, fmt.Errorf("addr type %s requires compressed pub key", addrType)
+		}
+	default:
+		return nil, fmt.Errorf("invalid or unsupported addr type for pub key: %s", addrType)
+	}
+
+	addrs := &Addrs{}
+	addrs.P2pkh, err = singleKeyAddr(serializedPubKey, AddrTypeP2pkhOrP2sh, params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate new address from pub key: %w", err)
+		return nil, err
 	}
 
-	addr := addressPubKey.EncodeAddress()
+	if !uncompressed {
+		if addrs.P2wpkhP2sh, err = singleKeyAddr(serializedPubKey, AddrTypeP2wpkhP2sh, params); err != nil {
+			return nil, err
+		}
+
+		if addrs.P2wpkh, err = singleKeyAddr(serializedPubKey, AddrTypeP2wpkh, params); err != nil {
+			return nil, err
+		}
+
+		if addrs.P2tr, err = singleKeyAddr(serializedPubKey, AddrTypeP2tr, params); err != nil {
+			return nil, err
+		}
+	}
 
 	key := &Key{
-		XPrv:      "",
-		XPub:      "",
-		PrvKeyWif: "",
-		PubKeyHex: keyString,
-		Addr:      addr,
-		Network:   NetworkTypeMainnet,
-		CoinType:  CoinTypeBtc,
+		PubKeyHex:    hex.EncodeToString(serializedPubKey),
+		Uncompressed: uncompressed,
+		Addr:         addrs.P2pkh,
+		Addrs:        addrs,
+		Network:      network,
+		CoinType:     CoinTypeBtc,
+		segWitNested: addrs.P2wpkhP2sh,
+		segWitBech32: addrs.P2wpkh,
+	}
+
+	if err := setAddr(key, addrType, nil, nil); err != nil {
+		return nil, err
 	}
 
 	return key, nil
@@ -354,94 +658,230 @@ func DecodePrivateWifKey(keyString string) (*Key, error) {
 		return nil, fmt.Errorf("failed to decode wif: %w", err)
 	}
 
-	var network string
-	for k, v := range netParams {
-		if wif.IsForNet(v) {
-			network = k
-			break
-		}
-	}
-
-	if len(network) == 0 {
-		return nil, fmt.Errorf("detected network is not supported, only btc mainnet and testnet keys are supported")
+	coin, network, err := wifCoin(wif)
+	if err != nil {
+		return nil, err
 	}
 
 	serializedPubKey := wif.SerializePubKey()
-	addressPubKey, err := btcutil.NewAddressPubKey(serializedPubKey, netParams[network])
+	addr, err := coin.encodeAddr(serializedPubKey, AddrTypeP2pkhOrP2sh, network.Params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate new address from pub key: %w", err)
 	}
 
-	addr := addressPubKey.EncodeAddress()
-
 	key := &Key{
-		XPrv:      "",
-		XPub:      "",
-		PrvKeyWif: keyString,
-		PubKeyHex: hex.EncodeToString(serializedPubKey),
-		Addr:      addr,
-		Network:   network,
-		CoinType:  CoinTypeBtc,
+		XPrv:         "",
+		XPub:         "",
+		PrvKeyWif:    keyString,
+		PubKeyHex:    hex.EncodeToString(serializedPubKey),
+		Uncompressed: !wif.CompressPubKey,
+		Addr:         addr,
+		Network:      network.Network,
+		CoinType:     coin.Name,
 	}
 
 	return key, nil
 }
 
+// UncompressKey switches the key to uncompressed public key form for interop
+// with legacy wallets. Private key is WIF encoded with uncompressed flag and
+// address is the p2pkh address of the uncompressed public key. Descriptor is
+// cleared since extended keys in descriptors imply compressed public keys
+func UncompressKey(key *Key) error {
+	if key.AddrType != AddrTypeLegacy {
+		return fmt.Errorf("uncompressed keys are only supported for %s addr type, got %s", AddrTypeLegacy, key.AddrType)
+	}
+
+	coin, err := CoinByName(key.CoinType)
+	if err != nil {
+		return err
+	}
+
+	network, err := coin.network(key.Network)
+	if err != nil {
+		return err
+	}
+
+	pubKeyBytes, err := hex.DecodeString(key.PubKeyHex)
+	if err != nil {
+		return fmt.Errorf("failed to decode pub key: %w", err)
+	}
+
+	pub, err := btcec.ParsePubKey(pubKeyBytes, btcec.S256())
+	if err != nil {
+		return fmt.Errorf("failed to parse pub key: %w", err)
+	}
+
+	serializedPubKey := pub.SerializeUncompressed()
+
+	if len(key.PrvKeyWif) > 0 {
+		wif, err := btcutil.DecodeWIF(key.PrvKeyWif)
+		if err != nil {
+			return fmt.Errorf("failed to decode wif: %w", err)
+		}
+
+		wif, err = btcutil.NewWIF(wif.PrivKey, network.Params, false)
+		if err != nil {
+			return fmt.Errorf("failed to generate wif formatted prv key: %w", err)
+		}
+
+		key.PrvKeyWif = wif.String()
+	}
+
+	addr, err := coin.encodeAddr(serializedPubKey, AddrTypeP2pkhOrP2sh, network.Params)
+	if err != nil {
+		return fmt.Errorf("failed to generate new address from pub key: %w", err)
+	}
+
+	key.PubKeyHex = hex.EncodeToString(serializedPubKey)
+	key.Uncompressed = true
+	key.Addr = addr
+	key.Descriptor = ""
+
+	return nil
+}
+
 func DecodeExtendedKey(keyString string) (*Key, error) {
-	key, err := Derive(keyString, "m")
+	return DecodeExtendedKeyWithCoin(keyString, "")
+}
+
+// DecodeExtendedKeyWithCoin decodes extended key as a key of the coin,
+// which is detected from the key version when empty
+func DecodeExtendedKeyWithCoin(keyString string, coin string) (*Key, error) {
+	key, err := DeriveWithCoin(keyString, "m", coin, "", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to self derive extended key: %w", err)
 	}
 
+	key.DerivationPath = ""
+
 	return key, nil
 }
 
 func Derive(keyString string, derivationPath string) (*Key, error) {
-	bip32Key, err := bip32.B58Deserialize(keyString)
+	return DeriveWithScript(keyString, derivationPath, nil)
+}
+
+// DeriveWithScript derives a child key similar to Derive, however, uses
+// the script for generating addresses when input key version corresponds
+// to p2wsh or p2wsh-p2sh address types
+func DeriveWithScript(keyString string, derivationPath string, script *Script) (*Key, error) {
+	return DeriveWithNetwork(keyString, derivationPath, "", script)
+}
+
+// DeriveWithNetwork derives a child key similar to DeriveWithScript, however,
+// addresses are generated for the network instead of the network detected
+// from the key version. This is required for networks such as signet and
+// regtest that share key versions with testnet
+func DeriveWithNetwork(keyString string, derivationPath string, network string, script *Script) (*Key, error) {
+	return DeriveWithCoin(keyString, derivationPath, "", network, script)
+}
+
+// DeriveWithCoin derives a child key similar to DeriveWithNetwork, however,
+// keys are treated as keys of the coin instead of the coin detected from
+// the key version. This is required for coins such as eth and bch that
+// share xpub and xprv versions with btc, which is detected by default
+func DeriveWithCoin(keyString string, derivationPath string, coin string, network string, script *Script) (*Key, error) {
+	g, err := newDeriveKeyGenerator(keyString, coin, network, script)
 	if err != nil {
-		return nil, fmt.Errorf("failed to deserialize key: %w", err)
+		return nil, err
 	}
 
-	versions, ok := versionToVersions[hex.EncodeToString(bip32Key.Version)]
-	if !ok {
-		return nil, fmt.Errorf("failed to identity valid key version: %w", err)
+	bip32Key, err := extendedKeyToDerivedExtendedKey(g.rootKey, derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive extended key: %w", err)
 	}
 
-	bip32.PublicWalletVersion = mustDecodeHex(versions[0])
-	bip32.PrivateWalletVersion = mustDecodeHex(versions[1])
+	indices, err := ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse derivation path: %w", err)
+	}
 
-	bip32Key, err = extendedKeyToDerivedExtendedKey(bip32Key, derivationPath)
+	return g.key(bip32Key, indices)
+}
+
+// DeriveRange derives child keys similar to DeriveWithNetwork, however,
+// the derivation path can contain range elements as described in NewRange.
+// Coin and network are detected from the key version when empty
+func DeriveRange(keyString string, derivationPath string, coin string, network string, script *Script, startIndex, count uint32, fn func(key *Key) error) error {
+	g, err := newDeriveKeyGenerator(keyString, coin, network, script)
 	if err != nil {
-		return nil, fmt.Errorf("failed to derive extended key: %w", err)
+		return err
 	}
 
-	key, err := extendedKeyToKey(bip32Key)
+	elements, err := parseDerivationPathRange(derivationPath, startIndex, count)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get key from extended key")
+		return fmt.Errorf("failed to parse derivation path: %w", err)
 	}
 
-	switch versionToAddrType[hex.EncodeToString(bip32Key.Version)] {
-	case AddrTypeP2pkhOrP2sh:
-		key.segWitNested, key.segWitBech32 = "", ""
-	case AddrTypeP2wpkhP2sh, AddrTypeP2wshP2sh:
-		key.Addr, key.segWitNested, key.segWitBech32 = key.segWitNested, "", ""
-	case AddrTypeP2wpkh, AddrTypeP2wsh:
-		key.Addr, key.segWitNested, key.segWitBech32 = key.segWitBech32, "", ""
+	if g.script.isExplicit() && !isSingleIndex(elements) {
+		return fmt.Errorf("explicit witness script describes a single address and cannot be used for a range of keys")
 	}
 
-	switch versionToAddrType[hex.EncodeToString(bip32Key.Version)] {
-	case AddrTypeP2pkhOrP2sh:
-		key.AddrType = AddrTypeLegacy
-	case AddrTypeP2wpkhP2sh, AddrTypeP2wshP2sh:
-		key.AddrType = fmt.Sprintf("%s, %s", AddrTypeSegWitCompatible, AddrTypeP2sh)
-	case AddrTypeP2wpkh, AddrTypeP2wsh:
-		key.AddrType = fmt.Sprintf("%s, %s", AddrTypeSegWitNative, AddrTypeBech32)
+	return g.walk(g.rootKey, elements, nil, fn)
+}
+
+// newDeriveKeyGenerator deserializes input extended key as the root key
+// and infers address type from the key version. Coin and network are
+// also inferred from the key version when empty
+func newDeriveKeyGenerator(keyString string, coinType string, network string, script *Script) (*keyGenerator, error) {
+	bip32Key, err := bip32.B58Deserialize(keyString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize key: %w", err)
 	}
 
-	return key, nil
+	version := hex.EncodeToString(bip32Key.Version)
+	addrType, ok := versionToAddrType[version]
+	if !ok {
+		return nil, fmt.Errorf("failed to identity valid key version")
+	}
+
+	coin := versionToCoin[version]
+	if len(coinType) > 0 {
+		coin, err = CoinByName(coinType)
+		if err != nil {
+			return nil, err
+		}
+
+		coinNetwork, coinAddrType, ok := coin.versionOf(version)
+		if !ok {
+			return nil, fmt.Errorf("key version %s does not belong to %s", version, coin.Name)
+		}
+
+		addrType = coinAddrType
+		if len(network) == 0 {
+			network = coinNetwork
+		}
+	}
+
+	g := &keyGenerator{
+		rootKey:     bip32Key,
+		coin:        coin,
+		network:     strings.ToLower(network),
+		addrType:    addrType,
+		script:      script,
+		descriptors: make(map[string]string),
+	}
+
+	if err := g.setOrigin(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
 }
 
 func extendedKeyToDerivedExtendedKey(key *bip32.Key, derivationPath string) (*bip32.Key, error) {
+	indices, err := ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return deriveChildKeys(key, indices)
+}
+
+// ParseDerivationPath parses derivation path such as m/44h/0h/0h/0/0
+// into successive child indices
+func ParseDerivationPath(derivationPath string) ([]uint32, error) {
 	derivationPath = strings.Trim(strings.ToLower(derivationPath), "/")
 	if len(derivationPath) == 0 {
 		derivationPath = "m"
@@ -455,132 +895,417 @@ func extendedKeyToDerivedExtendedKey(key *bip32.Key, derivationPath string) (*bi
 		return nil, fmt.Errorf("invalid derivation path, must start with m: %s", derivationPath)
 	}
 
+	indices := make([]uint32, 0, len(parts)-1)
+	for i, part := range parts {
+		if i == 0 {
+			continue
+		}
+
+		idx, err := parseChildIndex(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path at index %d: %s, %w", i, derivationPath, err)
+		}
+
+		indices = append(indices, idx)
+	}
+
+	return indices, nil
+}
+
+// parseChildIndex parses a single derivation path element such as 0, 0h or 0'
+func parseChildIndex(part string) (uint32, error) {
+	if len(part) == 0 {
+		return 0, fmt.Errorf("empty child index")
+	}
+
+	var idx uint32
+	if part[len(part)-1] == '\'' || part[len(part)-1] == 'h' {
+		idx = bip32.FirstHardenedChild
+		part = part[:len(part)-1]
+	}
+
+	index, err := strconv.ParseUint(part, 10, 31)
+	if err != nil {
+		return 0, err
+	}
+
+	return idx + uint32(index), nil
+}
+
+// indexSpan is an inclusive span of child indices
+type indexSpan struct {
+	first, last uint32
+}
+
+// parseDerivationPathRange parses derivation path that may contain range
+// elements into spans of candidate child indices for each path element. Range elements
+// can be a span such as 0-999, a set such as {0,1,5-9} or a wildcard * that
+// expands to count indices beginning with startIndex. A trailing h or ' marks
+// all indices of the element as hardened
+func parseDerivationPathRange(derivationPath string, startIndex, count uint32) ([][]indexSpan, error) {
+	derivationPath = strings.Trim(strings.ToLower(derivationPath), "/")
+	if len(derivationPath) == 0 {
+		derivationPath = "m"
+	}
+
+	// split on slashes that are not within a set
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range derivationPath {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				parts = append(parts, derivationPath[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, derivationPath[start:])
+
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path, must start with m: %s", derivationPath)
+	}
+
+	elements := make([][]indexSpan, 0, len(parts)-1)
 	for i, part := range parts {
 		if i == 0 {
 			continue
 		}
-		var idx uint32
+
+		element, err := parseChildIndexRange(part, startIndex, count)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path at index %d: %s, %w", i, derivationPath, err)
+		}
+
+		elements = append(elements, element)
+	}
+
+	return elements, nil
+}
+
+// parseChildIndexRange parses a single derivation path element
+// that may be a span, a set or a wildcard
+func parseChildIndexRange(part string, startIndex, count uint32) ([]indexSpan, error) {
+	if len(part) == 0 {
+		return nil, fmt.Errorf("empty child index")
+	}
+
+	var hardened uint32
+	if strings.HasPrefix(part, "{") || strings.HasPrefix(part, "*") {
 		if part[len(part)-1] == '\'' || part[len(part)-1] == 'h' {
-			idx = bip32.FirstHardenedChild
+			hardened = bip32.FirstHardenedChild
 			part = part[:len(part)-1]
 		}
+	}
 
-		index, err := strconv.ParseInt(part, 10, 64)
-		if err != nil || index < 0 {
-			return nil, fmt.Errorf("invalid derivation path at index %d: %s, %w", i, derivationPath, err)
+	var spans []indexSpan
+	switch {
+	case part == "*":
+		if count == 0 {
+			return nil, fmt.Errorf("count must be positive for wildcard")
+		}
+
+		if uint64(startIndex)+uint64(count) > uint64(bip32.FirstHardenedChild) {
+			return nil, fmt.Errorf("wildcard range exceeds max child index")
+		}
+
+		spans = append(spans, indexSpan{
+			first: hardened + startIndex,
+			last:  hardened + startIndex + count - 1,
+		})
+	case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+		for _, item := range strings.Split(part[1:len(part)-1], ",") {
+			span, err := parseChildIndexSpan(item)
+			if err != nil {
+				return nil, err
+			}
+
+			spans = append(spans, indexSpan{first: hardened + span.first, last: hardened + span.last})
+		}
+	default:
+		span, err := parseChildIndexSpan(part)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, span)
+	}
+
+	return spans, nil
+}
+
+// parseChildIndexSpan parses a child index such as 0h or a span such as 0-9
+// or 0h-9h. Hardened marker on either end of the span applies to entire span
+func parseChildIndexSpan(part string) (indexSpan, error) {
+	ends := strings.Split(part, "-")
+	switch len(ends) {
+	case 1:
+		idx, err := parseChildIndex(part)
+		if err != nil {
+			return indexSpan{}, err
+		}
+		return indexSpan{first: idx, last: idx}, nil
+	case 2:
+		var hardened bool
+		for i, end := range ends {
+			if strings.HasSuffix(end, "h") || strings.HasSuffix(end, "'") {
+				hardened = true
+				ends[i] = end[:len(end)-1]
+			}
 		}
 
-		idx += uint32(index)
+		first, err := parseChildIndex(ends[0])
+		if err != nil {
+			return indexSpan{}, err
+		}
+
+		last, err := parseChildIndex(ends[1])
+		if err != nil {
+			return indexSpan{}, err
+		}
+
+		if first > last {
+			return indexSpan{}, fmt.Errorf("invalid child index span %s", part)
+		}
+
+		if hardened {
+			first, last = first+bip32.FirstHardenedChild, last+bip32.FirstHardenedChild
+		}
+
+		return indexSpan{first: first, last: last}, nil
+	default:
+		return indexSpan{}, fmt.Errorf("invalid child index span %s", part)
+	}
+}
+
+// FormatDerivationPath formats child indices as a derivation path
+// using h to denote hardened indices
+func FormatDerivationPath(indices []uint32) string {
+	parts := make([]string, 0, len(indices)+1)
+	parts = append(parts, "m")
+	for _, idx := range indices {
+		if idx >= bip32.FirstHardenedChild {
+			parts = append(parts, fmt.Sprintf("%dh", idx-bip32.FirstHardenedChild))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d", idx))
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// hardenedLen returns the length of the derivation path
+// prefix that ends with the last hardened index
+func hardenedLen(indices []uint32) int {
+	n := 0
+	for i, idx := range indices {
+		if idx >= bip32.FirstHardenedChild {
+			n = i + 1
+		}
+	}
+
+	return n
+}
+
+// deriveChildKeys successively derives child keys for the indices
+// retaining the key version family of the input key
+func deriveChildKeys(key *bip32.Key, indices []uint32) (*bip32.Key, error) {
+	pubVersion, prvVersion, err := keyVersionsOf(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, idx := range indices {
 		key, err = key.NewChildKey(idx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate %d child key: %w", i, err)
+			return nil, fmt.Errorf("failed to generate %d child key: %w", i+1, err)
+		}
+
+		// bip32 pkg assigns child key version from its globals,
+		// so parent key version is carried over explicitly
+		if key.IsPrivate {
+			key.Version = prvVersion
+		} else {
+			key.Version = pubVersion
 		}
 	}
 
 	return key, nil
 }
 
-func extendedKeyToKey(key *bip32.Key) (*Key, error) {
-	var network string
-	var params *chaincfg.Params
+// keyVersionsOf returns public and private key versions corresponding
+// to the version of the input extended key
+func keyVersionsOf(key *bip32.Key) ([]byte, []byte, error) {
+	versions, ok := versionToVersions[hex.EncodeToString(key.Version)]
+	if !ok {
+		return nil, nil, fmt.Errorf("failed to identify valid key version")
+	}
 
-	if _, ok := mainnetVersions[hex.EncodeToString(key.Version)]; ok {
-		params = &chaincfg.MainNetParams
-		network = NetworkTypeMainnet
-	} else {
-		if _, ok := testnetVersions[hex.EncodeToString(key.Version)]; ok {
-			params = &chaincfg.TestNet3Params
-			network = NetworkTypeTestnet
-		}
+	return mustDecodeHex(versions[0]), mustDecodeHex(versions[1]), nil
+}
+
+// publicKeyOf returns public extended key retaining the key version
+// family of the input key, i.e., xprv maps to xpub, zprv to zpub etc.
+func publicKeyOf(key *bip32.Key) (*bip32.Key, error) {
+	pubVersion, _, err := keyVersionsOf(key)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey := key.PublicKey()
+	pubKey.Version = pubVersion
+
+	return pubKey, nil
+}
+
+// extendedKeyToKey converts extended key of the coin on the network
+// for output. Coin and network are detected from the key version
+// when nil or empty respectively
+func extendedKeyToKey(key *bip32.Key, coin *Coin, network string) (*Key, error) {
+	version := hex.EncodeToString(key.Version)
+	if coin == nil {
+		coin = versionToCoin[version]
 	}
 
 	if len(network) == 0 {
-		return nil, fmt.Errorf("unsupported network and/or coin type, accepted values are BTC:%v",
-			[]string{NetworkTypeMainnet, NetworkTypeTestnet})
+		network = versionToNetwork[version]
 	}
 
+	if coin == nil || len(network) == 0 {
+		return nil, fmt.Errorf("unsupported network and/or coin type, accepted values are %v", CoinNames())
+	}
+
+	coinNetwork, err := coin.network(network)
+	if err != nil {
+		return nil, err
+	}
+
+	if !coinNetwork.hasVersion(version) {
+		return nil, fmt.Errorf("key version %s does not belong to %s %s", version, coin.Name, network)
+	}
+	params := coinNetwork.Params
+
 	var pubKey *bip32.Key
 	var prvKey *bip32.Key
 
 	var prvKeyString string
 	var pubKeyString string
 
-	var addr string
-	var prvKeyWif string
+	var addr, segwitBech32, segwitNested string
+	var prvKeyWif, prvKeyHex string
 
 	if key.IsPrivate {
 		prvKey = key
-		pubKey = key.PublicKey()
+		p, err := publicKeyOf(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get public key: %w", err)
+		}
+		pubKey = p
 	} else {
 		pubKey = key
 	}
 
 	pubKeyString = fmt.Sprintf("%s", pubKey)
-	var serializedPubKey []byte
+	pubKeyHex := hex.EncodeToString(pubKey.Key)
 
 	if prvKey != nil {
 		prvKeyString = fmt.Sprintf("%s", prvKey)
+	}
 
-		prv, _ := btcec.PrivKeyFromBytes(btcec.S256(), prvKey.Key)
-
-		wif, err := btcutil.NewWIF(prv, params, true)
+	// ethereum keys are presented as raw hex private key and
+	// uncompressed public key
+	if coin.Name == CoinTypeEth {
+		p, err := btcec.ParsePubKey(pubKey.Key, btcec.S256())
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate wif formatted prv key: %w", err)
+			return nil, fmt.Errorf("failed to parse pubkey: %w", err)
 		}
-		prvKeyWif = wif.String()
 
-		serializedPubKey = wif.SerializePubKey()
-	} else {
-		p, err := btcec.ParsePubKey(pubKey.Key, btcec.S256())
+		serializedPubKey := p.SerializeUncompressed()
+		pubKeyHex = hex.EncodeToString(serializedPubKey)
+
+		addr, err = coin.encodeAddr(serializedPubKey, AddrTypeEth, params)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse pubkey: %w", err)
+			return nil, fmt.Errorf("failed to generate new address from pub key: %w", err)
 		}
 
-		serializedPubKey = p.SerializeCompressed()
-	}
+		if prvKey != nil {
+			prvKeyHex = hex.EncodeToString(prvKey.Key)
+		}
+	} else {
+		var serializedPubKey []byte
 
-	addressPubKey, err := btcutil.NewAddressPubKey(serializedPubKey, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate new address from pub key: %w", err)
-	}
+		if prvKey != nil {
+			prv, _ := btcec.PrivKeyFromBytes(btcec.S256(), prvKey.Key)
 
-	addr = addressPubKey.EncodeAddress()
+			wif, err := btcutil.NewWIF(prv, params, true)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate wif formatted prv key: %w", err)
+			}
+			prvKeyWif = wif.String()
 
-	// generate a normal p2wkh address from the pubkey hash
-	witnessProg := btcutil.Hash160(serializedPubKey)
-	addressWitnessPubKeyHash, err := btcutil.NewAddressWitnessPubKeyHash(witnessProg, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate new address witness pub key hash: %w", err)
-	}
+			serializedPubKey = wif.SerializePubKey()
+		} else {
+			p, err := btcec.ParsePubKey(pubKey.Key, btcec.S256())
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse pubkey: %w", err)
+			}
 
-	segwitBech32 := addressWitnessPubKeyHash.EncodeAddress()
+			serializedPubKey = p.SerializeCompressed()
+		}
 
-	// generate an address which is
-	// backwards compatible to Bitcoin nodes running 0.6.0 onwards, but
-	// allows us to take advantage of segwit's scripting improvments,
-	// and malleability fixes.
-	serializedScript, err := txscript.PayToAddrScript(addressWitnessPubKeyHash)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate pay to addr script: %w", err)
+		addr, err = coin.encodeAddr(serializedPubKey, AddrTypeP2pkhOrP2sh, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate new address from pub key: %w", err)
+		}
+
+		// generate native and nested segwit addresses for coins
+		// that support segwit
+		if len(params.Bech32HRPSegwit) > 0 {
+			segwitBech32, err = coin.encodeAddr(serializedPubKey, AddrTypeP2wpkh, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate new address witness pub key hash: %w", err)
+			}
+
+			// generate an address which is
+			// backwards compatible to Bitcoin nodes running 0.6.0 onwards, but
+			// allows us to take advantage of segwit's scripting improvments,
+			// and malleability fixes.
+			segwitNested, err = coin.encodeAddr(serializedPubKey, AddrTypeP2wpkhP2sh, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate new address script hash: %w", err)
+			}
+		}
 	}
 
-	addressScriptHash, err := btcutil.NewAddressScriptHash(serializedScript, params)
+	fingerprint, err := fingerprintOf(pubKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate new address script hash: %w", err)
+		return nil, fmt.Errorf("failed to get key fingerprint: %w", err)
 	}
 
-	segwitNested := addressScriptHash.EncodeAddress()
+	depth := key.Depth
 
 	return &Key{
+		KeyMetadata: KeyMetadata{
+			Depth:             &depth,
+			ParentFingerprint: hex.EncodeToString(key.FingerPrint),
+			ChildIndex: strings.TrimPrefix(
+				FormatDerivationPath([]uint32{binary.BigEndian.Uint32(key.ChildNumber)}), "m/"),
+			ChainCode:   hex.EncodeToString(key.ChainCode),
+			Fingerprint: hex.EncodeToString(fingerprint),
+		},
 		XPrv:         prvKeyString,
 		XPub:         pubKeyString,
 		PrvKeyWif:    prvKeyWif,
-		PubKeyHex:    hex.EncodeToString(pubKey.Key),
+		PrvKeyHex:    prvKeyHex,
+		PubKeyHex:    pubKeyHex,
 		Addr:         addr,
 		segWitNested: segwitNested,
 		segWitBech32: segwitBech32,
 		Network:      network,
-		CoinType:     CoinTypeBtc,
+		CoinType:     coin.Name,
 	}, nil
 }
 
@@ -673,6 +1398,10 @@ func Validate(keyString string) error {
 		if x.Cmp(z) != 1 {
 			return fmt.Errorf("key is not in 1:n-1, key is too small")
 		}
+	} else {
+		if _, err := btcec.ParsePubKey(key.Key, btcec.S256()); err != nil {
+			return fmt.Errorf("invalid public key: %w", err)
+		}
 	}
 
 	return nil
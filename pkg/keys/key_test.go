@@ -0,0 +1,463 @@
+package keys
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+// TestNew_Concurrent derives keys of mixed versions in parallel and
+// checks that each key is serialized with its own version prefix.
+// Run with -race to detect shared state between derivations.
+func TestNew_Concurrent(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		network  string
+		addrType string
+		xPrv     string
+		xPub     string
+	}{
+		{network: NetworkTypeMainnet, addrType: AddrTypeLegacy, xPrv: "xprv", xPub: "xpub"},
+		{network: NetworkTypeMainnet, addrType: AddrTypeSegWitCompatible, xPrv: "yprv", xPub: "ypub"},
+		{network: NetworkTypeMainnet, addrType: AddrTypeSegWitNative, xPrv: "zprv", xPub: "zpub"},
+		{network: NetworkTypeTestnet, addrType: AddrTypeLegacy, xPrv: "tprv", xPub: "tpub"},
+		{network: NetworkTypeTestnet, addrType: AddrTypeSegWitCompatible, xPrv: "uprv", xPub: "upub"},
+		{network: NetworkTypeTestnet, addrType: AddrTypeSegWitNative, xPrv: "vprv", xPub: "vpub"},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 1024)
+
+	for i := 0; i < 4; i++ {
+		for _, test := range tests {
+			wg.Add(1)
+			go func(network, addrType, xPrv, xPub string) {
+				defer wg.Done()
+
+				key, err := New(
+					&Config{
+						Seed:           seed,
+						Network:        network,
+						DerivationPath: "auto",
+						AddrType:       addrType,
+					},
+				)
+				if err != nil {
+					errs <- err.Error()
+					return
+				}
+
+				if !strings.HasPrefix(key.XPrv, xPrv) {
+					errs <- "expected " + xPrv + ", got " + key.XPrv
+				}
+
+				if !strings.HasPrefix(key.XPub, xPub) {
+					errs <- "expected " + xPub + ", got " + key.XPub
+				}
+
+				// derive further from private and public extended keys
+				for _, keyString := range []string{key.XPrv, key.XPub} {
+					derived, err := Derive(keyString, "m/0/1")
+					if err != nil {
+						errs <- err.Error()
+						return
+					}
+
+					if len(derived.XPrv) > 0 && !strings.HasPrefix(derived.XPrv, xPrv) {
+						errs <- "expected " + xPrv + ", got " + derived.XPrv
+					}
+
+					if !strings.HasPrefix(derived.XPub, xPub) {
+						errs <- "expected " + xPub + ", got " + derived.XPub
+					}
+				}
+			}(test.network, test.addrType, test.xPrv, test.xPub)
+		}
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// TestDerive_KeyMetadata uses test vector 1 from
+// https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki#test-vector-1
+func TestDerive_KeyMetadata(t *testing.T) {
+	master := "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+
+	tests := []struct {
+		derivationPath    string
+		depth             uint8
+		parentFingerprint string
+		childIndex        string
+		fingerprint       string
+		chainCode         string
+	}{
+		{
+			derivationPath:    "m",
+			depth:             0,
+			parentFingerprint: "00000000",
+			childIndex:        "0",
+			fingerprint:       "3442193e",
+			chainCode:         "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508",
+		},
+		{
+			derivationPath:    "m/0h",
+			depth:             1,
+			parentFingerprint: "3442193e",
+			childIndex:        "0h",
+			fingerprint:       "5c1bd648",
+			chainCode:         "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141",
+		},
+		{
+			derivationPath:    "m/0h/1",
+			depth:             2,
+			parentFingerprint: "5c1bd648",
+			childIndex:        "1",
+			fingerprint:       "bef5a2f9",
+			chainCode:         "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19",
+		},
+	}
+
+	for _, test := range tests {
+		key, err := Derive(master, test.derivationPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.Depth == nil || *key.Depth != test.depth {
+			t.Fatal("expected depth", test.depth, ", got", key.Depth, ", for", test.derivationPath)
+		}
+
+		if key.ParentFingerprint != test.parentFingerprint {
+			t.Fatal("expected", test.parentFingerprint, ", got", key.ParentFingerprint, ", for", test.derivationPath)
+		}
+
+		if key.ChildIndex != test.childIndex {
+			t.Fatal("expected", test.childIndex, ", got", key.ChildIndex, ", for", test.derivationPath)
+		}
+
+		if key.Fingerprint != test.fingerprint {
+			t.Fatal("expected", test.fingerprint, ", got", key.Fingerprint, ", for", test.derivationPath)
+		}
+
+		if key.ChainCode != test.chainCode {
+			t.Fatal("expected", test.chainCode, ", got", key.ChainCode, ", for", test.derivationPath)
+		}
+	}
+}
+
+func TestNew_KeyOrigin(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "m/84'/0'/0'/0/0", AddrType: AddrTypeBip84})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "3442193e"; key.MasterFingerprint != expected {
+		t.Fatal("expected", expected, ", got", key.MasterFingerprint)
+	}
+
+	if expected := "[3442193e/84h/0h/0h/0/0]"; key.KeyOrigin != expected {
+		t.Fatal("expected", expected, ", got", key.KeyOrigin)
+	}
+
+	// derivation path is normalized the same way as for range paths
+	if expected := "m/84h/0h/0h/0/0"; key.DerivationPath != expected {
+		t.Fatal("expected", expected, ", got", key.DerivationPath)
+	}
+}
+
+// TestNewRange checks that keys generated over a range derivation
+// path match keys generated individually
+func TestNewRange(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	tests := []struct {
+		derivationPath string
+		startIndex     uint32
+		count          uint32
+		expected       []string
+	}{
+		{
+			derivationPath: "m/84h/0h/0h/0/0-2",
+			expected:       []string{"m/84h/0h/0h/0/0", "m/84h/0h/0h/0/1", "m/84h/0h/0h/0/2"},
+		},
+		{
+			derivationPath: "m/84h/0h/0h/{0,1}/*",
+			startIndex:     5,
+			count:          2,
+			expected:       []string{"m/84h/0h/0h/0/5", "m/84h/0h/0h/0/6", "m/84h/0h/0h/1/5", "m/84h/0h/0h/1/6"},
+		},
+		{
+			derivationPath: "m/84h/0h/{0-1}h/0/0",
+			expected:       []string{"m/84h/0h/0h/0/0", "m/84h/0h/1h/0/0"},
+		},
+	}
+
+	for _, test := range tests {
+		var derived []*Key
+		if err := NewRange(
+			&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: test.derivationPath, AddrType: AddrTypeBip84},
+			test.startIndex,
+			test.count,
+			func(key *Key) error {
+				derived = append(derived, key)
+				return nil
+			},
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(derived) != len(test.expected) {
+			t.Fatal("expected", len(test.expected), "keys, got", len(derived), ", for", test.derivationPath)
+		}
+
+		for i, derivationPath := range test.expected {
+			key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: derivationPath, AddrType: AddrTypeBip84})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if derived[i].DerivationPath != derivationPath {
+				t.Fatal("expected", derivationPath, ", got", derived[i].DerivationPath)
+			}
+
+			if derived[i].Addr != key.Addr || derived[i].Descriptor != key.Descriptor || derived[i].KeyOrigin != key.KeyOrigin {
+				t.Fatal("expected", key.Addr, ", got", derived[i].Addr, ", for", derivationPath)
+			}
+		}
+	}
+
+	// keys of large spans are streamed without expanding the span
+	errStop := errors.New("stop")
+	var n int
+	if err := NewRange(
+		&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "m/84h/0h/0h/0/0-2147483647", AddrType: AddrTypeBip84},
+		0,
+		0,
+		func(key *Key) error {
+			if n++; n == 2 {
+				return errStop
+			}
+			return nil
+		},
+	); !errors.Is(err, errStop) || n != 2 {
+		t.Fatal("expected streaming to stop after 2 keys, got", n, err)
+	}
+
+	for _, derivationPath := range []string{"m/0/2-1", "m/0/{0,x}", "m/0/*", "n/0-1"} {
+		if err := NewRange(
+			&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: derivationPath, AddrType: AddrTypeBip84},
+			0,
+			0,
+			func(key *Key) error { return nil },
+		); err == nil {
+			t.Fatal("expected error for", derivationPath)
+		}
+	}
+}
+
+func TestNew_Networks(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	tests := []struct {
+		network  string
+		addrType string
+		addr     string
+	}{
+		{network: NetworkTypeTestnet, addrType: AddrTypeBip84, addr: "tb1q6rz28mcfaxtmd6v789l9rrlrusdprr9pqcpvkl"},
+		{network: NetworkTypeSignet, addrType: AddrTypeBip84, addr: "tb1q6rz28mcfaxtmd6v789l9rrlrusdprr9pqcpvkl"},
+		{network: NetworkTypeRegtest, addrType: AddrTypeBip84, addr: "bcrt1q6rz28mcfaxtmd6v789l9rrlrusdprr9pz3cppk"},
+		{network: NetworkTypeRegtest, addrType: AddrTypeBip86, addr: "bcrt1p"},
+		{network: NetworkTypeRegtest, addrType: AddrTypeBip44, addr: "m"},
+	}
+
+	for _, test := range tests {
+		key, err := New(&Config{Seed: seed, Network: test.network, DerivationPath: "auto", AddrType: test.addrType})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(key.Addr, test.addr) || key.Network != test.network {
+			t.Fatal("expected", test.addr, test.network, ", got", key.Addr, key.Network)
+		}
+
+		if !strings.HasPrefix(key.DerivationPath, "m/") || strings.Split(key.DerivationPath, "/")[2] != "1h" {
+			t.Fatal("expected coin type 1h, got", key.DerivationPath)
+		}
+
+		// key versions are shared with testnet, hence network is
+		// detected as testnet unless specified
+		account := key.XPrv
+		derived, err := Derive(account, "m")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if derived.Network != NetworkTypeTestnet {
+			t.Fatal("expected testnet, got", derived.Network)
+		}
+
+		derived, err = DeriveWithNetwork(account, "m", test.network, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if test.addrType != AddrTypeBip86 && derived.Addr != key.Addr {
+			t.Fatal("expected", key.Addr, ", got", derived.Addr)
+		}
+
+		if _, err := DeriveWithNetwork(account, "m", NetworkTypeMainnet, nil); err == nil {
+			t.Fatal("expected error for mainnet derivation of testnet key")
+		}
+
+		decoded, err := DecodePrivateWifKey(key.PrvKeyWif)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoded.Network != NetworkTypeTestnet {
+			t.Fatal("expected testnet, got", decoded.Network)
+		}
+	}
+
+	if _, network, err := AddrScript("bcrt1q6rz28mcfaxtmd6v789l9rrlrusdprr9pz3cppk"); err != nil || network != NetworkTypeRegtest {
+		t.Fatal("expected regtest, got", network, err)
+	}
+}
+
+func TestDecodePublicHex(t *testing.T) {
+	// generator point in compressed and uncompressed form
+	compressed := "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	uncompressed := "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798" +
+		"483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+
+	key, err := DecodePublicHex(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key.Uncompressed || key.Network != NetworkTypeMainnet || key.Addr != "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH" {
+		t.Fatal("unexpected key", key)
+	}
+
+	if key.Addrs.P2wpkh != "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4" ||
+		key.Addrs.P2tr != "bc1pmfr3p9j00pfxjh0zmgp99y8zftmd3s5pmedqhyptwy6lm87hf5sspknck9" ||
+		len(key.Addrs.P2wpkhP2sh) == 0 {
+		t.Fatal("unexpected addrs", key.Addrs)
+	}
+
+	key, err = DecodePublicHexWithNetwork(compressed, "RegTest", AddrTypeBip84)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key.Network != NetworkTypeRegtest ||
+		key.Addr != "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080" || key.Addr != key.Addrs.P2wpkh {
+		t.Fatal("unexpected addr", key.Addr)
+	}
+
+	key, err = DecodePublicHex(uncompressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !key.Uncompressed || key.PubKeyHex != uncompressed || key.Addr != "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm" {
+		t.Fatal("unexpected key", key)
+	}
+
+	if len(key.Addrs.P2wpkh) > 0 || len(key.Addrs.P2tr) > 0 {
+		t.Fatal("expected no segwit addrs for uncompressed pub key", key.Addrs)
+	}
+
+	if _, err := DecodePublicHexWithNetwork(uncompressed, NetworkTypeMainnet, AddrTypeP2wpkh); err == nil {
+		t.Fatal("expected error for segwit addr of uncompressed pub key")
+	}
+
+	if _, err := DecodePublicHexWithNetwork(compressed, NetworkTypeMainnet, AddrTypeP2wsh); err == nil {
+		t.Fatal("expected error for p2wsh addr type")
+	}
+
+	if _, err := DecodePublicHexWithNetwork(compressed, "xyz", AddrTypeP2pkhOrP2sh); err == nil {
+		t.Fatal("expected error for invalid network")
+	}
+}
+
+func TestUncompressKey(t *testing.T) {
+	// private key 1 in uncompressed and compressed wif
+	decoded, err := DecodePrivateWifKey("5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !decoded.Uncompressed || decoded.Addr != "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm" || len(decoded.PubKeyHex) != 130 {
+		t.Fatal("unexpected uncompressed key", decoded)
+	}
+
+	decoded, err = DecodePrivateWifKey("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Uncompressed || decoded.Addr != "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH" {
+		t.Fatal("unexpected compressed key", decoded)
+	}
+
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	key, err := New(&Config{
+		Seed:           seed,
+		Network:        NetworkTypeMainnet,
+		DerivationPath: "auto",
+		AddrType:       AddrTypeLegacy,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UncompressKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if !key.Uncompressed || !strings.HasPrefix(key.PrvKeyWif, "5") || len(key.Descriptor) > 0 {
+		t.Fatal("unexpected uncompressed key", key)
+	}
+
+	decoded, err = DecodePrivateWifKey(key.PrvKeyWif)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Addr != key.Addr || decoded.PubKeyHex != key.PubKeyHex {
+		t.Fatal("expected", key.Addr, ", got", decoded.Addr)
+	}
+
+	key, err = New(&Config{
+		Seed:           seed,
+		Network:        NetworkTypeMainnet,
+		DerivationPath: "auto",
+		AddrType:       AddrTypeBip84,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UncompressKey(key); err == nil {
+		t.Fatal("expected error for segwit addr type")
+	}
+}
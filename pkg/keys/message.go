@@ -0,0 +1,289 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// messageSignatureHeader is prefixed to messages before hashing so
+// that a signed message cannot be a valid transaction signature
+const messageSignatureHeader = "Bitcoin Signed Message:\n"
+
+// BIP-137 header bytes, each followed by three more values
+// for the remaining recovery ids
+// https://github.com/bitcoin/bips/blob/master/bip-0137.mediawiki
+const (
+	msgHeaderP2pkhUncompressed = 27
+	msgHeaderP2pkhCompressed   = 31
+	msgHeaderP2wpkhP2sh        = 35
+	msgHeaderP2wpkh            = 39
+	msgHeaderMax               = 42
+)
+
+// SignedMessage is the output of message signing and verification
+type SignedMessage struct {
+	Addr      string `json:"addr" yaml:"addr"`
+	AddrType  string `json:"addrType" yaml:"addrType"`
+	PubKeyHex string `json:"pubKeyHex,omitempty" yaml:"pubKeyHex,omitempty"`
+	Message   string `json:"message" yaml:"message"`
+	Signature string `json:"signature" yaml:"signature"`
+	Format    string `json:"format,omitempty" yaml:"format,omitempty"`
+}
+
+// SignMessage signs the message using wif encoded private key and returns
+// base64 encoded compact signature along with the address it commits to.
+// The signature header byte encodes the address type per BIP-137, where
+// supported address types are p2pkh, p2wpkh-p2sh and p2wpkh including
+// their aliases
+func SignMessage(prvKeyWif, addrType, message string) (*SignedMessage, error) {
+	wif, err := btcutil.DecodeWIF(prvKeyWif)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode wif: %w", err)
+	}
+
+	params, err := wifParams(wif)
+	if err != nil {
+		return nil, err
+	}
+
+	addrType = normalizeAddrType(addrType)
+	header, err := messageHeader(addrType, wif.CompressPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey := wif.SerializePubKey()
+	addr, err := singleKeyAddr(pubKey, addrType, params)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := messageHash(message)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := btcec.SignCompact(btcec.S256(), wif.PrivKey, hash, wif.CompressPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	// signature is produced with a p2pkh header, which is moved to
+	// the range of the address type keeping the recovery id
+	sig[0] = header + (sig[0]-msgHeaderP2pkhUncompressed)%4
+
+	return &SignedMessage{
+		Addr:      addr,
+		AddrType:  addrType,
+		PubKeyHex: hex.EncodeToString(pubKey),
+		Message:   message,
+		Signature: base64.StdEncoding.EncodeToString(sig),
+		Format:    MessageFormatLegacy,
+	}, nil
+}
+
+// VerifyMessage verifies base64 encoded compact signature of the message
+// against the address and returns the recovered public key. Address type
+// is inferred from the signature header, however, compressed p2pkh headers
+// are also accepted for segwit addresses since some wallets, such as
+// Electrum, do not use BIP-137 header ranges
+func VerifyMessage(addr, signature, message string) (*SignedMessage, error) {
+	_, network, err := AddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	params := netParams[network]
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 signature: %w", err)
+	}
+
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("invalid signature length %d, expected 65 bytes", len(sig))
+	}
+
+	header := sig[0]
+	if header < msgHeaderP2pkhUncompressed || header > msgHeaderMax {
+		return nil, fmt.Errorf("invalid signature header byte %d", header)
+	}
+
+	var addrTypes []string
+	switch {
+	case header < msgHeaderP2pkhCompressed:
+		addrTypes = []string{AddrTypeP2pkhOrP2sh}
+	case header < msgHeaderP2wpkhP2sh:
+		addrTypes = []string{AddrTypeP2pkhOrP2sh, AddrTypeP2wpkhP2sh, AddrTypeP2wpkh}
+	case header < msgHeaderP2wpkh:
+		addrTypes = []string{AddrTypeP2wpkhP2sh}
+	default:
+		addrTypes = []string{AddrTypeP2wpkh}
+	}
+
+	// recovery expects p2pkh headers, with segwit types
+	// always using compressed public keys
+	compact := make([]byte, len(sig))
+	copy(compact, sig)
+	compact[0] = msgHeaderP2pkhCompressed + (header-msgHeaderP2pkhUncompressed)%4
+	if header < msgHeaderP2pkhCompressed {
+		compact[0] = header
+	}
+
+	hash, err := messageHash(message)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, compressed, err := btcec.RecoverCompact(btcec.S256(), compact, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover public key from signature: %w", err)
+	}
+
+	pubKey := pub.SerializeUncompressed()
+	if compressed {
+		pubKey = pub.SerializeCompressed()
+	}
+
+	for _, addrType := range addrTypes {
+		recovered, err := singleKeyAddr(pubKey, addrType, params)
+		if err != nil {
+			return nil, err
+		}
+
+		// bech32 addresses are case insensitive and can be
+		// provided in all uppercase
+		if recovered == addr ||
+			(addrType == AddrTypeP2wpkh && strings.EqualFold(recovered, addr)) {
+			return &SignedMessage{
+				Addr:      addr,
+				AddrType:  addrType,
+				PubKeyHex: hex.EncodeToString(pubKey),
+				Message:   message,
+				Signature: signature,
+				Format:    MessageFormatLegacy,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("signature verification failed, recovered public key %x does not match address %s", pubKey, addr)
+}
+
+// messageHash returns double sha256 hash of the message
+// prefixed with the message signature header
+func messageHash(message string) ([]byte, error) {
+	var b bytes.Buffer
+	if err := wire.WriteVarString(&b, 0, messageSignatureHeader); err != nil {
+		return nil, fmt.Errorf("failed to serialize message header: %w", err)
+	}
+
+	if err := wire.WriteVarString(&b, 0, message); err != nil {
+		return nil, fmt.Errorf("failed to serialize message: %w", err)
+	}
+
+	return chainhash.DoubleHashB(b.Bytes()), nil
+}
+
+// messageHeader returns BIP-137 header byte for the address
+// type without the recovery id
+func messageHeader(addrType string, compressed bool) (byte, error) {
+	switch addrType {
+	case AddrTypeP2pkhOrP2sh:
+		if !compressed {
+			return msgHeaderP2pkhUncompressed, nil
+		}
+		return msgHeaderP2pkhCompressed, nil
+	case AddrTypeP2wpkhP2sh, AddrTypeP2wpkh:
+		if !compressed {
+			return 0, fmt.Errorf("segwit address types require compressed public key")
+		}
+
+		if addrType == AddrTypeP2wpkhP2sh {
+			return msgHeaderP2wpkhP2sh, nil
+		}
+		return msgHeaderP2wpkh, nil
+	default:
+		return 0, fmt.Errorf("invalid or unsupported addr type for message signing: %s. allowed addr types are %v", addrType,
+			[]string{AddrTypeP2pkhOrP2sh, AddrTypeP2wpkhP2sh, AddrTypeP2wpkh},
+		)
+	}
+}
+
+// SingleKeyAddr returns p2pkh, p2wpkh-p2sh, p2wpkh or p2tr address,
+// including their aliases, for the hex encoded public key
+func SingleKeyAddr(pubKeyHex, addrType, network string) (string, error) {
+	params, ok := netParams[network]
+	if !ok {
+		return "", fmt.Errorf("invalid or unsupported network: %s", network)
+	}
+
+	pubKey, err := hex.DecodeString(pubKeyHex)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode pub key: %w", err)
+	}
+
+	return singleKeyAddr(pubKey, normalizeAddrType(addrType), params)
+}
+
+// singleKeyAddr returns p2pkh, p2wpkh-p2sh, p2wpkh or p2tr
+// address for the serialized public key
+func singleKeyAddr(pubKey []byte, addrType string, params *chaincfg.Params) (string, error) {
+	pubKeyHash := btcutil.Hash160(pubKey)
+
+	switch addrType {
+	case AddrTypeP2pkhOrP2sh:
+		addr, err := btcutil.NewAddressPubKeyHash(pubKeyHash, params)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate p2pkh address: %w", err)
+		}
+		return addr.EncodeAddress(), nil
+	case AddrTypeP2wpkh, AddrTypeP2wpkhP2sh:
+		addr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, params)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate p2wpkh address: %w", err)
+		}
+
+		if addrType == AddrTypeP2wpkh {
+			return addr.EncodeAddress(), nil
+		}
+
+		redeemScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate pay to addr script: %w", err)
+		}
+
+		nested, err := btcutil.NewAddressScriptHash(redeemScript, params)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate p2wpkh-p2sh address: %w", err)
+		}
+		return nested.EncodeAddress(), nil
+	case AddrTypeP2tr:
+		_, outputKey, err := taprootOutputKey(pubKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to tweak taproot internal key: %w", err)
+		}
+		return taprootAddr(outputKey, params)
+	default:
+		return "", fmt.Errorf("invalid or unsupported single key addr type: %s", addrType)
+	}
+}
+
+// wifParams returns network params for the wif key
+func wifParams(wif *btcutil.WIF) (*chaincfg.Params, error) {
+	for _, network := range networkTypes {
+		if params := netParams[network]; wif.IsForNet(params) {
+			return params, nil
+		}
+	}
+
+	return nil, fmt.Errorf("detected network is not supported, only btc keys are supported")
+}
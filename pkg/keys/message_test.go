@@ -0,0 +1,141 @@
+package keys
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+// TestSignMessage_BitcoinCore uses the vector from Bitcoin Core
+// functional test rpc_signmessagewithprivkey.py
+func TestSignMessage_BitcoinCore(t *testing.T) {
+	wif := "cUeKHd5orzT3mz8P9pxyREHfsWtVfgsfDjiZZBcjUBAaGk1BTj7N"
+	addr := "mpLQjfK79b7CCV4VMJWEWAj5Mpx8Up5zxB"
+	message := "This is just a test message"
+	signature := "INbVnW4e6PeRmsv2Qgu8NuopvrVjkcxob+sX8OcZG0SALhWybUjzMLPdAsXI46YZGb0KQTRii+wWIQzRpG/U+S0="
+
+	signed, err := SignMessage(wif, AddrTypeLegacy, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signed.Addr != addr {
+		t.Fatal("expected", addr, ", got", signed.Addr)
+	}
+
+	if signed.Signature != signature {
+		t.Fatal("expected", signature, ", got", signed.Signature)
+	}
+
+	if _, err := VerifyMessage(addr, signature, message); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyMessage(addr, signature, message+"."); err == nil {
+		t.Fatal("expected verification to fail for modified message")
+	}
+}
+
+func TestSignMessage_AddrTypes(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	tests := []struct {
+		addrType string
+		header   byte
+	}{
+		{addrType: AddrTypeBip44, header: msgHeaderP2pkhCompressed},
+		{addrType: AddrTypeBip49, header: msgHeaderP2wpkhP2sh},
+		{addrType: AddrTypeBip84, header: msgHeaderP2wpkh},
+	}
+
+	for _, test := range tests {
+		key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "auto", AddrType: test.addrType})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signed, err := SignMessage(key.PrvKeyWif, test.addrType, "hello world")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if signed.Addr != key.Addr {
+			t.Fatal("expected", key.Addr, ", got", signed.Addr)
+		}
+
+		sig, err := base64.StdEncoding.DecodeString(signed.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sig[0] < test.header || sig[0] > test.header+3 {
+			t.Fatal("expected header in range of", test.header, ", got", sig[0], ", for", test.addrType)
+		}
+
+		verified, err := VerifyMessage(key.Addr, signed.Signature, "hello world")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if verified.PubKeyHex != key.PubKeyHex {
+			t.Fatal("expected", key.PubKeyHex, ", got", verified.PubKeyHex)
+		}
+
+		// bech32 addresses are accepted in uppercase
+		if test.header == msgHeaderP2wpkh {
+			if _, err := VerifyMessage(strings.ToUpper(key.Addr), signed.Signature, "hello world"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// compressed p2pkh header is accepted for segwit addresses
+		sig[0] = msgHeaderP2pkhCompressed + (sig[0]-msgHeaderP2pkhUncompressed)%4
+		if _, err := VerifyMessage(key.Addr, base64.StdEncoding.EncodeToString(sig), "hello world"); err != nil {
+			t.Fatal(err)
+		}
+
+		// segwit headers do not match other address types
+		// of the same key
+		if test.header != msgHeaderP2pkhCompressed {
+			other, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: key.DerivationPath, AddrType: AddrTypeBip44})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := VerifyMessage(other.Addr, signed.Signature, "hello world"); err == nil {
+				t.Fatal("expected verification to fail for", other.Addr)
+			}
+		}
+	}
+}
+
+func TestSignMessage_Invalid(t *testing.T) {
+	// uncompressed key cannot sign for segwit addresses
+	uncompressed := "5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ"
+	if _, err := SignMessage(uncompressed, AddrTypeP2wpkh, "hello"); err == nil {
+		t.Fatal("expected error for uncompressed key and p2wpkh")
+	}
+
+	signed, err := SignMessage(uncompressed, AddrTypeLegacy, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "1GAehh7TsJAHuUAeKZcXf5CnwuGuGgyX2S"; signed.Addr != expected {
+		t.Fatal("expected", expected, ", got", signed.Addr)
+	}
+
+	if _, err := VerifyMessage(signed.Addr, signed.Signature, "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SignMessage(uncompressed, AddrTypeP2tr, "hello"); err == nil {
+		t.Fatal("expected error for p2tr addr type")
+	}
+
+	if _, err := VerifyMessage(signed.Addr, "aGVsbG8=", "hello"); err == nil {
+		t.Fatal("expected error for short signature")
+	}
+}
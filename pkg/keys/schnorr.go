@@ -0,0 +1,152 @@
+package keys
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// schnorrSign signs the 32 byte hash using the private key per
+// https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki#default-signing
+// where aux is 32 bytes of auxiliary random data
+func schnorrSign(privKey *big.Int, hash, aux []byte) ([]byte, error) {
+	curve := btcec.S256()
+
+	if privKey.Sign() == 0 || privKey.Cmp(curve.N) >= 0 {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	px, py := curve.ScalarBaseMult(paddedBytes(privKey))
+	d := new(big.Int).Set(privKey)
+	if py.Bit(0) == 1 {
+		d.Sub(curve.N, d)
+	}
+
+	t := new(big.Int).SetBytes(taggedHash("BIP0340/aux", aux))
+	t.Xor(t, d)
+
+	k := new(big.Int).SetBytes(taggedHash("BIP0340/nonce", paddedBytes(t), paddedBytes(px), hash))
+	k.Mod(k, curve.N)
+	if k.Sign() == 0 {
+		return nil, fmt.Errorf("invalid schnorr nonce")
+	}
+
+	rx, ry := curve.ScalarBaseMult(paddedBytes(k))
+	if ry.Bit(0) == 1 {
+		k.Sub(curve.N, k)
+	}
+
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", paddedBytes(rx), paddedBytes(px), hash))
+	e.Mod(e, curve.N)
+
+	s := new(big.Int).Mul(e, d)
+	s.Add(s, k)
+	s.Mod(s, curve.N)
+
+	sig := append(paddedBytes(rx), paddedBytes(s)...)
+
+	if err := schnorrVerify(paddedBytes(px), hash, sig); err != nil {
+		return nil, fmt.Errorf("failed to verify generated signature: %w", err)
+	}
+
+	return sig, nil
+}
+
+// schnorrVerify verifies 64 byte signature of the 32 byte hash
+// against x-only public key per
+// https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki#verification
+func schnorrVerify(pubKey, hash, sig []byte) error {
+	curve := btcec.S256()
+
+	if len(sig) != 64 {
+		return fmt.Errorf("invalid schnorr signature length %d", len(sig))
+	}
+
+	px, py, err := liftX(pubKey)
+	if err != nil {
+		return err
+	}
+
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if r.Cmp(curve.P) >= 0 || s.Cmp(curve.N) >= 0 {
+		return fmt.Errorf("schnorr signature values out of range")
+	}
+
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", sig[:32], pubKey, hash))
+	e.Mod(e, curve.N)
+
+	// R = s⋅G - e⋅P
+	sx, sy := curve.ScalarBaseMult(paddedBytes(s))
+	ex, ey := curve.ScalarMult(px, py, paddedBytes(e))
+	ey.Sub(curve.P, ey)
+	rx, ry := curve.Add(sx, sy, ex, ey)
+
+	if rx.Sign() == 0 && ry.Sign() == 0 {
+		return fmt.Errorf("schnorr signature verification failed")
+	}
+
+	if ry.Bit(0) == 1 || !bytes.Equal(paddedBytes(rx), sig[:32]) {
+		return fmt.Errorf("schnorr signature verification failed")
+	}
+
+	return nil
+}
+
+// liftX returns the point with even y coordinate for the x-only public key
+func liftX(pubKey []byte) (*big.Int, *big.Int, error) {
+	curve := btcec.S256()
+
+	if len(pubKey) != 32 {
+		return nil, nil, fmt.Errorf("invalid x-only public key length %d", len(pubKey))
+	}
+
+	x := new(big.Int).SetBytes(pubKey)
+	if x.Cmp(curve.P) >= 0 {
+		return nil, nil, fmt.Errorf("x-only public key exceeds field size")
+	}
+
+	// y² = x³ + 7
+	c := new(big.Int).Exp(x, big.NewInt(3), curve.P)
+	c.Add(c, curve.B)
+	c.Mod(c, curve.P)
+
+	y := new(big.Int).Exp(c, curve.QPlus1Div4(), curve.P)
+	if new(big.Int).Exp(y, big.NewInt(2), curve.P).Cmp(c) != 0 {
+		return nil, nil, fmt.Errorf("x-only public key is not on curve")
+	}
+
+	if y.Bit(0) == 1 {
+		y.Sub(curve.P, y)
+	}
+
+	return x, y, nil
+}
+
+// taprootTweakPrivKey applies BIP-341 key tweak without any script
+// path to the private key, such that it corresponds to the output key
+// returned by taprootOutputKey
+func taprootTweakPrivKey(privKey *btcec.PrivateKey) (*big.Int, error) {
+	curve := btcec.S256()
+
+	d := new(big.Int).Set(privKey.D)
+	px, py := curve.ScalarBaseMult(paddedBytes(d))
+	if py.Bit(0) == 1 {
+		d.Sub(curve.N, d)
+	}
+
+	t := new(big.Int).SetBytes(taggedHash("TapTweak", paddedBytes(px)))
+	if t.Cmp(curve.N) >= 0 {
+		return nil, fmt.Errorf("taproot tweak exceeds curve order")
+	}
+
+	d.Add(d, t)
+	d.Mod(d, curve.N)
+	if d.Sign() == 0 {
+		return nil, fmt.Errorf("invalid tweaked private key")
+	}
+
+	return d, nil
+}
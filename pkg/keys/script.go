@@ -0,0 +1,197 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+)
+
+// maxMultisigKeys is the max number of public keys allowed
+// in a p2wsh multisig witness script
+const maxMultisigKeys = 20
+
+// Script describes witness script used for generating p2wsh and p2wsh-p2sh
+// addresses. Either an explicit witness script or a sorted multisig template
+// is expected. For the multisig template, the public key of the key being
+// generated is included along with the cosigner public keys and all keys are
+// sorted lexicographically per BIP-67. Cosigner extended keys are added using
+// AddCosignerKey and are derived along the non-hardened part of the derivation
+// path of the key being generated
+type Script struct {
+	WitnessScript []byte
+	Threshold     int
+	PubKeys       [][]byte
+	xKeys         []*descriptorKey
+}
+
+// AddCosignerKey adds cosigner key to the multisig template. Key can be
+// a hex encoded pub key or an extended key optionally prefixed with its
+// key origin, such as [fingerprint/48h/0h/0h/2h]xpub...
+func (s *Script) AddCosignerKey(keyString string) error {
+	keyString = strings.TrimSpace(keyString)
+	if b, err := hex.DecodeString(keyString); err == nil {
+		s.PubKeys = append(s.PubKeys, b)
+		return nil
+	}
+
+	key, err := parseDescriptorKey(keyString, false)
+	if err != nil {
+		return err
+	}
+
+	if key.xKey == nil {
+		s.PubKeys = append(s.PubKeys, key.pubKey)
+		return nil
+	}
+
+	if len(key.indices) > 0 || key.wildcard {
+		return fmt.Errorf("derivation path is not allowed after cosigner extended key")
+	}
+
+	if key.xKey, err = publicKeyOf(key.xKey); err != nil {
+		return fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	s.xKeys = append(s.xKeys, key)
+	return nil
+}
+
+// cosignerPubKeys returns compressed cosigner pub keys where the
+// extended keys are derived along the non-hardened indices
+func (s *Script) cosignerPubKeys(indices []uint32) ([][]byte, error) {
+	pubKeys := make([][]byte, 0, len(s.PubKeys)+len(s.xKeys))
+	for _, p := range s.PubKeys {
+		pub, err := btcec.ParsePubKey(p, btcec.S256())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cosigner pub key %x: %w", p, err)
+		}
+		pubKeys = append(pubKeys, pub.SerializeCompressed())
+	}
+
+	for _, key := range s.xKeys {
+		xKey, err := deriveChildKeys(key.xKey, indices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive cosigner key: %w", err)
+		}
+		pubKeys = append(pubKeys, xKey.Key)
+	}
+
+	return pubKeys, nil
+}
+
+// witnessScript returns the witness script that includes the input public
+// key, which is derived along the input non-hardened indices
+func (s *Script) witnessScript(pubKey []byte, indices []uint32) ([]byte, error) {
+	if s == nil {
+		return nil, fmt.Errorf("witness script or multisig template is required")
+	}
+
+	if len(s.WitnessScript) > 0 {
+		if s.Threshold > 0 || len(s.PubKeys) > 0 || len(s.xKeys) > 0 {
+			return nil, fmt.Errorf("witness script and multisig template are mutually exclusive")
+		}
+
+		if err := checkMultisigScript(s.WitnessScript, pubKey); err != nil {
+			return nil, err
+		}
+
+		return s.WitnessScript, nil
+	}
+
+	cosigners, err := s.cosignerPubKeys(indices)
+	if err != nil {
+		return nil, err
+	}
+
+	return multisigScript(s.Threshold, append([][]byte{pubKey}, cosigners...), true)
+}
+
+// checkMultisigScript checks that the witness script is a standard
+// multisig script with the public key being one of its keys
+func checkMultisigScript(witnessScript, pubKey []byte) error {
+	if txscript.GetScriptClass(witnessScript) != txscript.MultiSigTy {
+		return fmt.Errorf("witness script is not a multisig script")
+	}
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(witnessScript, &chaincfg.MainNetParams)
+	if err != nil {
+		return fmt.Errorf("failed to parse multisig witness script: %w", err)
+	}
+
+	for _, addr := range addrs {
+		if bytes.Equal(addr.ScriptAddress(), pubKey) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("witness script does not include public key %x", pubKey)
+}
+
+// isExplicit returns true if an explicit witness script is provided,
+// which describes a single address and cannot be used for a range
+func (s *Script) isExplicit() bool {
+	return s != nil && len(s.WitnessScript) > 0
+}
+
+// multisigScript returns m-of-n multisig script for the public keys,
+// which are sorted lexicographically per BIP-67 if sorted is true
+func multisigScript(threshold int, pubKeys [][]byte, sorted bool) ([]byte, error) {
+	if len(pubKeys) > maxMultisigKeys {
+		return nil, fmt.Errorf("too many keys in multisig template, max allowed is %d", maxMultisigKeys)
+	}
+
+	if threshold < 1 || threshold > len(pubKeys) {
+		return nil, fmt.Errorf("invalid multisig threshold %d, must be between 1 and %d", threshold, len(pubKeys))
+	}
+
+	if sorted {
+		pubKeys = append([][]byte{}, pubKeys...)
+		sort.Slice(pubKeys, func(i, j int) bool {
+			return bytes.Compare(pubKeys[i], pubKeys[j]) < 0
+		})
+
+		for i := 1; i < len(pubKeys); i++ {
+			if bytes.Equal(pubKeys[i-1], pubKeys[i]) {
+				return nil, fmt.Errorf("duplicate pub key in multisig template: %x", pubKeys[i])
+			}
+		}
+	}
+
+	builder := txscript.NewScriptBuilder().AddInt64(int64(threshold))
+	for _, pubKey := range pubKeys {
+		builder.AddData(pubKey)
+	}
+	builder.AddInt64(int64(len(pubKeys))).AddOp(txscript.OP_CHECKMULTISIG)
+
+	return builder.Script()
+}
+
+// witnessScriptHashAddrs returns native p2wsh address and p2wsh address
+// nested in p2sh for the input witness script
+func witnessScriptHashAddrs(witnessScript []byte, params *chaincfg.Params) (string, string, error) {
+	witnessProg := sha256.Sum256(witnessScript)
+	addressWitnessScriptHash, err := btcutil.NewAddressWitnessScriptHash(witnessProg[:], params)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate new address witness script hash: %w", err)
+	}
+
+	redeemScript, err := txscript.PayToAddrScript(addressWitnessScriptHash)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate pay to addr script: %w", err)
+	}
+
+	addressScriptHash, err := btcutil.NewAddressScriptHash(redeemScript, params)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate new address script hash: %w", err)
+	}
+
+	return addressWitnessScriptHash.EncodeAddress(), addressScriptHash.EncodeAddress(), nil
+}
@@ -0,0 +1,328 @@
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcutil"
+)
+
+// TestWitnessScriptHashAddrs uses P2WSH example from
+// https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki#examples
+func TestWitnessScriptHashAddrs(t *testing.T) {
+	pubKey, err := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	witnessScript := append(append([]byte{0x21}, pubKey...), 0xac)
+
+	p2wsh, _, err := witnessScriptHashAddrs(witnessScript, netParams[NetworkTypeMainnet])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3"; p2wsh != expected {
+		t.Fatal("expected", expected, ", got", p2wsh)
+	}
+
+	p2wsh, _, err = witnessScriptHashAddrs(witnessScript, netParams[NetworkTypeTestnet])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7"; p2wsh != expected {
+		t.Fatal("expected", expected, ", got", p2wsh)
+	}
+}
+
+// TestScript_ExplicitWitnessScript checks that explicit witness scripts
+// are required to be multisig scripts including the key and are only
+// used for a single address
+func TestScript_ExplicitWitnessScript(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "m/0/0", AddrType: AddrTypeP2wsh})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubKey, err := hex.DecodeString(key.PubKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cosigner, err := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	multisig, err := multisigScript(1, [][]byte{pubKey, cosigner}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := multisigScript(1, [][]byte{cosigner}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		witnessScript []byte
+		valid         bool
+	}{
+		{name: "multisig", witnessScript: multisig, valid: true},
+		{name: "multisig without key", witnessScript: other},
+		{name: "p2pk", witnessScript: append(append([]byte{0x21}, pubKey...), 0xac)},
+		{name: "key hash in data push", witnessScript: append(append([]byte{0x14}, btcutil.Hash160(pubKey)...), 0x87)},
+	}
+
+	for _, test := range tests {
+		key, err := New(
+			&Config{
+				Seed:           seed,
+				Network:        NetworkTypeMainnet,
+				DerivationPath: "m/0/0",
+				AddrType:       AddrTypeP2wsh,
+				Script:         &Script{WitnessScript: test.witnessScript},
+			},
+		)
+		if !test.valid {
+			if err == nil {
+				t.Fatal("expected error for", test.name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(test.name, err)
+		}
+
+		if len(key.Addr) == 0 {
+			t.Fatal("expected address for", test.name)
+		}
+	}
+
+	err = NewRange(
+		&Config{
+			Seed:           seed,
+			Network:        NetworkTypeMainnet,
+			DerivationPath: "m/0/*",
+			AddrType:       AddrTypeP2wsh,
+			Script:         &Script{WitnessScript: multisig},
+		},
+		0,
+		2,
+		func(key *Key) error { return nil },
+	)
+	if err == nil {
+		t.Fatal("expected error for explicit witness script with a range of keys")
+	}
+}
+
+// TestNew_SortedMultisig checks that each cosigner arrives at the
+// same p2wsh address irrespective of the order of pub keys
+func TestNew_SortedMultisig(t *testing.T) {
+	seeds := []string{
+		"000102030405060708090a0b0c0d0e0f",
+		"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		"4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be",
+	}
+
+	pubKeys := make([][]byte, len(seeds))
+	for i, s := range seeds {
+		seed, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "m/0", AddrType: AddrTypeP2wsh})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(key.Addr) > 0 {
+			t.Fatal("expected no address without witness script, got", key.Addr)
+		}
+
+		if pubKeys[i], err = hex.DecodeString(key.PubKeyHex); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var addrs []string
+	for i, s := range seeds {
+		seed, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var cosigners [][]byte
+		for j := range pubKeys {
+			if j != i {
+				cosigners = append(cosigners, pubKeys[j])
+			}
+		}
+
+		for _, addrType := range []string{AddrTypeP2wsh, AddrTypeP2wshP2sh} {
+			key, err := New(
+				&Config{
+					Seed:           seed,
+					Network:        NetworkTypeMainnet,
+					DerivationPath: "m/0",
+					AddrType:       addrType,
+					Script:         &Script{Threshold: 2, PubKeys: cosigners},
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			addrs = append(addrs, key.Addr)
+		}
+	}
+
+	for i := 2; i < len(addrs); i++ {
+		if addrs[i] != addrs[i%2] {
+			t.Fatal("expected", addrs[i%2], ", got", addrs[i])
+		}
+	}
+}
+
+// TestNew_SortedMultisigExtendedKeys uses 2-of-3 p2wsh sortedmulti with
+// cosigner account keys at m/48h/0h/0h/2h from BIP-32 test vector seeds.
+// Each cosigner derives the same addresses, which match the addresses
+// derived from the descriptor and from the multisig script built using
+// child pub keys of each seed
+func TestNew_SortedMultisigExtendedKeys(t *testing.T) {
+	seeds := []string{
+		"000102030405060708090a0b0c0d0e0f",
+		"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		"4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be",
+	}
+
+	accountPath := "m/48h/0h/0h/2h"
+	expectedDescriptor := "wsh(sortedmulti(2," +
+		"[3442193e/48h/0h/0h/2h]xpub6E64WfdQwBGz85XhbZryr9gUGUPBgoSu5WV6tJWpzAvgAmpVpdPHkT3XYm9R5J6MeWzvLQoz4q845taC9Q28XutbptxAmg7q8QPkjvTL4oi/0/*," +
+		"[bd16bee5/48h/0h/0h/2h]xpub6DwQ4gBCmJZM3TaKogP41tpjuEwnMH2nWEi3PFev37LfsWPvjZrh1GfAG8xvoDYMPWGKG1oBPMCfKpkVbJtUHRaqRdCb6X6o1e9PQTVK88a/0/*," +
+		"[41d63b50/48h/0h/0h/2h]xpub6DuE9tmT2f7qUpcAK7bBvrhS1uf8x2c4paV6tdGRD1htEcueVE2xk9ynFQfQZMK7tNNfhspnFQsd6HkJnhGzkZyDVhdCvcBsf3fenyt5qde/0/*" +
+		"))#kj3qwa7d"
+	expectedAddrs := []string{
+		"bc1q2drk5mz22swkwv86g5aq6hh77j5x6q0uynvrt45a3g5n3vm200tq3yt6rq",
+		"bc1qc9z645y6eegfr0sj05c38hflrdftdh6wdlydpemmhpxh0842q3fq8g5e4x",
+	}
+
+	accountKeys := make([]string, len(seeds))
+	childPubKeys := make([][][]byte, len(expectedAddrs))
+	for i, s := range seeds {
+		seed, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: accountPath, AddrType: AddrTypeP2wsh})
+		if err != nil {
+			t.Fatal(err)
+		}
+		accountKeys[i] = key.KeyOrigin + key.XPub
+
+		for index := range expectedAddrs {
+			key, err := New(
+				&Config{
+					Seed:           seed,
+					Network:        NetworkTypeMainnet,
+					DerivationPath: fmt.Sprintf("%s/0/%d", accountPath, index),
+					AddrType:       AddrTypeP2wsh,
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pubKey, err := hex.DecodeString(key.PubKeyHex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			childPubKeys[index] = append(childPubKeys[index], pubKey)
+		}
+	}
+
+	for index, pubKeys := range childPubKeys {
+		witnessScript, err := multisigScript(2, pubKeys, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p2wsh, _, err := witnessScriptHashAddrs(witnessScript, netParams[NetworkTypeMainnet])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if p2wsh != expectedAddrs[index] {
+			t.Fatal("expected", expectedAddrs[index], ", got", p2wsh)
+		}
+	}
+
+	for i, s := range seeds {
+		seed, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		script := &Script{Threshold: 2}
+		for j := range accountKeys {
+			if j != i {
+				if err := script.AddCosignerKey(accountKeys[j]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		var generated []*Key
+		if err := NewRange(
+			&Config{
+				Seed:           seed,
+				Network:        NetworkTypeMainnet,
+				DerivationPath: accountPath + "/0/*",
+				AddrType:       AddrTypeP2wsh,
+				Script:         script,
+			},
+			0,
+			uint32(len(expectedAddrs)),
+			func(key *Key) error {
+				generated = append(generated, key)
+				return nil
+			},
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		for index, key := range generated {
+			if key.Addr != expectedAddrs[index] {
+				t.Fatal("expected", expectedAddrs[index], ", got", key.Addr)
+			}
+
+			if i == 0 && key.Descriptor != expectedDescriptor {
+				t.Fatal("expected", expectedDescriptor, ", got", key.Descriptor)
+			}
+
+			desc, err := ParseDescriptor(key.Descriptor)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			derived, err := desc.Derive(uint32(index))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if derived.Addr != key.Addr {
+				t.Fatal("expected", key.Addr, ", got", derived.Addr)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package keys
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// taprootWitnessVersion is the segwit version of p2tr outputs
+const taprootWitnessVersion = 1
+
+// taggedHash computes hash per
+// https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki#design
+func taggedHash(tag string, msgs ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+
+	return h.Sum(nil)
+}
+
+// taprootOutputKey applies BIP-341 key tweak to the internal key without
+// any script path, as specified in BIP-86, and returns x-only internal
+// and output keys
+func taprootOutputKey(pubKey []byte) ([]byte, []byte, error) {
+	curve := btcec.S256()
+
+	p, err := btcec.ParsePubKey(pubKey, curve)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse pub key: %w", err)
+	}
+
+	// internal key is x-only, i.e., the point with even y coordinate
+	px, py := p.X, p.Y
+	if py.Bit(0) == 1 {
+		py = new(big.Int).Sub(curve.P, py)
+	}
+	internalKey := paddedBytes(px)
+
+	t := new(big.Int).SetBytes(taggedHash("TapTweak", internalKey))
+	if t.Cmp(curve.N) >= 0 {
+		return nil, nil, fmt.Errorf("taproot tweak exceeds curve order")
+	}
+
+	tx, ty := curve.ScalarBaseMult(paddedBytes(t))
+	qx, _ := curve.Add(px, py, tx, ty)
+
+	return internalKey, paddedBytes(qx), nil
+}
+
+// taprootAddr returns bech32m encoded p2tr address for the x-only output key
+func taprootAddr(outputKey []byte, params *chaincfg.Params) (string, error) {
+	return encodeSegWitAddress(params.Bech32HRPSegwit, taprootWitnessVersion, outputKey)
+}
+
+// paddedBytes returns 32 byte big endian representation of the input
+func paddedBytes(n *big.Int) []byte {
+	b := make([]byte, 32)
+	return n.FillBytes(b)
+}
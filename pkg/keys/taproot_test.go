@@ -0,0 +1,71 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+// TestNew_Bip86 uses test vectors from
+// https://github.com/bitcoin/bips/blob/master/bip-0086.mediawiki#test-vectors
+func TestNew_Bip86(t *testing.T) {
+	seed := seeds.New("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+
+	account, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: "m/86h/0h/0h", AddrType: AddrTypeBip86})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "xprv9xgqHN7yz9MwCkxsBPN5qetuNdQSUttZNKw1dcYTV4mkaAFiBVGQziHs3NRSWMkCzvgjEe3n9xV8oYywvM8at9yRqyaZVz6TYYhX98VjsUk"; account.XPrv != expected {
+		t.Fatal("expected", expected, ", got", account.XPrv)
+	}
+
+	if expected := "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ"; account.XPub != expected {
+		t.Fatal("expected", expected, ", got", account.XPub)
+	}
+
+	tests := []struct {
+		derivationPath string
+		internalKey    string
+		outputKey      string
+		addr           string
+	}{
+		{
+			derivationPath: "auto",
+			internalKey:    "cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115",
+			outputKey:      "a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c",
+			addr:           "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+		},
+		{
+			derivationPath: "m/86h/0h/0h/0/1",
+			internalKey:    "83dfe85a3151d2517290da461fe2815591ef69f2b18a2ce63f01697a8b313145",
+			outputKey:      "a82f29944d65b86ae6b5e5cc75e294ead6c59391a1edc5e016e3498c67fc7bbb",
+			addr:           "bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh",
+		},
+		{
+			derivationPath: "m/86h/0h/0h/1/0",
+			internalKey:    "399f1b2f4393f29a18c937859c5dd8a77350103157eb880f02e8c08214277cef",
+			outputKey:      "882d74e5d0572d5a816cef0041a96b6c1de832f6f9676d9605c44d5e9a97d3dc",
+			addr:           "bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7",
+		},
+	}
+
+	for _, test := range tests {
+		key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: test.derivationPath, AddrType: AddrTypeP2tr})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.InternalKey != test.internalKey {
+			t.Fatal("expected", test.internalKey, ", got", key.InternalKey, ", for", test.derivationPath)
+		}
+
+		if key.OutputKey != test.outputKey {
+			t.Fatal("expected", test.outputKey, ", got", key.OutputKey, ", for", test.derivationPath)
+		}
+
+		if key.Addr != test.addr {
+			t.Fatal("expected", test.addr, ", got", key.Addr, ", for", test.derivationPath)
+		}
+	}
+}
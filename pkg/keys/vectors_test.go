@@ -0,0 +1,293 @@
+package keys
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+// bip32TestVector lists extended keys expected for a derivation
+// path from the seed
+type bip32TestVector struct {
+	seed           string
+	derivationPath string
+	xPub           string
+	xPrv           string
+}
+
+// bip32TestVectors are from
+// https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki#test-vectors
+var bip32TestVectors = []bip32TestVector{
+	// test vector 1
+	{
+		seed:           "000102030405060708090a0b0c0d0e0f",
+		derivationPath: "m",
+		xPub:           "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+		xPrv:           "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+	},
+	{
+		seed:           "000102030405060708090a0b0c0d0e0f",
+		derivationPath: "m/0H",
+		xPub:           "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+		xPrv:           "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+	},
+	{
+		seed:           "000102030405060708090a0b0c0d0e0f",
+		derivationPath: "m/0H/1",
+		xPub:           "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+		xPrv:           "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs",
+	},
+	{
+		seed:           "000102030405060708090a0b0c0d0e0f",
+		derivationPath: "m/0H/1/2H",
+		xPub:           "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+		xPrv:           "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM",
+	},
+	{
+		seed:           "000102030405060708090a0b0c0d0e0f",
+		derivationPath: "m/0H/1/2H/2",
+		xPub:           "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+		xPrv:           "xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334",
+	},
+	{
+		seed:           "000102030405060708090a0b0c0d0e0f",
+		derivationPath: "m/0H/1/2H/2/1000000000",
+		xPub:           "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+		xPrv:           "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76",
+	},
+	// test vector 2
+	{
+		seed:           "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		derivationPath: "m",
+		xPub:           "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+		xPrv:           "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U",
+	},
+	{
+		seed:           "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		derivationPath: "m/0",
+		xPub:           "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH",
+		xPrv:           "xprv9vHkqa6EV4sPZHYqZznhT2NPtPCjKuDKGY38FBWLvgaDx45zo9WQRUT3dKYnjwih2yJD9mkrocEZXo1ex8G81dwSM1fwqWpWkeS3v86pgKt",
+	},
+	{
+		seed:           "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		derivationPath: "m/0/2147483647H",
+		xPub:           "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a",
+		xPrv:           "xprv9wSp6B7kry3Vj9m1zSnLvN3xH8RdsPP1Mh7fAaR7aRLcQMKTR2vidYEeEg2mUCTAwCd6vnxVrcjfy2kRgVsFawNzmjuHc2YmYRmagcEPdU9",
+	},
+	{
+		seed:           "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		derivationPath: "m/0/2147483647H/1",
+		xPub:           "xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon",
+		xPrv:           "xprv9zFnWC6h2cLgpmSA46vutJzBcfJ8yaJGg8cX1e5StJh45BBciYTRXSd25UEPVuesF9yog62tGAQtHjXajPPdbRCHuWS6T8XA2ECKADdw4Ef",
+	},
+	{
+		seed:           "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		derivationPath: "m/0/2147483647H/1/2147483646H",
+		xPub:           "xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL",
+		xPrv:           "xprvA1RpRA33e1JQ7ifknakTFpgNXPmW2YvmhqLQYMmrj4xJXXWYpDPS3xz7iAxn8L39njGVyuoseXzU6rcxFLJ8HFsTjSyQbLYnMpCqE2VbFWc",
+	},
+	{
+		seed:           "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		derivationPath: "m/0/2147483647H/1/2147483646H/2",
+		xPub:           "xpub6FnCn6nSzZAw5Tw7cgR9bi15UV96gLZhjDstkXXxvCLsUXBGXPdSnLFbdpq8p9HmGsApME5hQTZ3emM2rnY5agb9rXpVGyy3bdW6EEgAtqt",
+		xPrv:           "xprvA2nrNbFZABcdryreWet9Ea4LvTJcGsqrMzxHx98MMrotbir7yrKCEXw7nadnHM8Dq38EGfSh6dqA9QWTyefMLEcBYJUuekgW4BYPJcr9E7j",
+	},
+	// test vector 3
+	{
+		seed:           "4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be",
+		derivationPath: "m",
+		xPub:           "xpub661MyMwAqRbcEZVB4dScxMAdx6d4nFc9nvyvH3v4gJL378CSRZiYmhRoP7mBy6gSPSCYk6SzXPTf3ND1cZAceL7SfJ1Z3GC8vBgp2epUt13",
+		xPrv:           "xprv9s21ZrQH143K25QhxbucbDDuQ4naNntJRi4KUfWT7xo4EKsHt2QJDu7KXp1A3u7Bi1j8ph3EGsZ9Xvz9dGuVrtHHs7pXeTzjuxBrCmmhgC6",
+	},
+	{
+		seed:           "4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be",
+		derivationPath: "m/0H",
+		xPub:           "xpub68NZiKmJWnxxS6aaHmn81bvJeTESw724CRDs6HbuccFQN9Ku14VQrADWgqbhhTHBaohPX4CjNLf9fq9MYo6oDaPPLPxSb7gwQN3ih19Zm4Y",
+		xPrv:           "xprv9uPDJpEQgRQfDcW7BkF7eTya6RPxXeJCqCJGHuCJ4GiRVLzkTXBAJMu2qaMWPrS7AANYqdq6vcBcBUdJCVVFceUvJFjaPdGZ2y9WACViL4L",
+	},
+	// test vector 4
+	{
+		seed:           "3ddd5602285899a946114506157c7997e5444528f3003f6134712147db19b678",
+		derivationPath: "m",
+		xPub:           "xpub661MyMwAqRbcGczjuMoRm6dXaLDEhW1u34gKenbeYqAix21mdUKJyuyu5F1rzYGVxyL6tmgBUAEPrEz92mBXjByMRiJdba9wpnN37RLLAXa",
+		xPrv:           "xprv9s21ZrQH143K48vGoLGRPxgo2JNkJ3J3fqkirQC2zVdk5Dgd5w14S7fRDyHH4dWNHUgkvsvNDCkvAwcSHNAQwhwgNMgZhLtQC63zxwhQmRv",
+	},
+	{
+		seed:           "3ddd5602285899a946114506157c7997e5444528f3003f6134712147db19b678",
+		derivationPath: "m/0H",
+		xPub:           "xpub69AUMk3qDBi3uW1sXgjCmVjJ2G6WQoYSnNHyzkmdCHEhSZ4tBok37xfFEqHd2AddP56Tqp4o56AePAgCjYdvpW2PU2jbUPFKsav5ut6Ch1m",
+		xPrv:           "xprv9vB7xEWwNp9kh1wQRfCCQMnZUEG21LpbR9NPCNN1dwhiZkjjeGRnaALmPXCX7SgjFTiCTT6bXes17boXtjq3xLpcDjzEuGLQBM5ohqkao9G",
+	},
+	{
+		seed:           "3ddd5602285899a946114506157c7997e5444528f3003f6134712147db19b678",
+		derivationPath: "m/0H/1H",
+		xPub:           "xpub6BJA1jSqiukeaesWfxe6sNK9CCGaujFFSJLomWHprUL9DePQ4JDkM5d88n49sMGJxrhpjazuXYWdMf17C9T5XnxkopaeS7jGk1GyyVziaMt",
+		xPrv:           "xprv9xJocDuwtYCMNAo3Zw76WENQeAS6WGXQ55RCy7tDJ8oALr4FWkuVoHJeHVAcAqiZLE7Je3vZJHxspZdFHfnBEjHqU5hG1Jaj32dVoS6XLT1",
+	},
+}
+
+// bip32InvalidKeys are from
+// https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki#test-vector-5
+var bip32InvalidKeys = []string{
+	"xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6LBpB85b3D2yc8sfvZU521AAwdZafEz7mnzBBsz4wKY5fTtTQBm",
+	"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFGTQQD3dC4H2D5GBj7vWvSQaaBv5cxi9gafk7NF3pnBju6dwKvH",
+	"xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6Txnt3siSujt9RCVYsx4qHZGc62TG4McvMGcAUjeuwZdduYEvFn",
+	"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFGpWnsj83BHtEy5Zt8CcDr1UiRXuWCmTQLxEK9vbz5gPstX92JQ",
+	"xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6N8ZMMXctdiCjxTNq964yKkwrkBJJwpzZS4HS2fxvyYUA4q2Xe4",
+	"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFAzHGBP2UuGCqWLTAPLcMtD9y5gkZ6Eq3Rjuahrv17fEQ3Qen6J",
+	"xprv9s2SPatNQ9Vc6GTbVMFPFo7jsaZySyzk7L8n2uqKXJen3KUmvQNTuLh3fhZMBoG3G4ZW1N2kZuHEPY53qmbZzCHshoQnNf4GvELZfqTUrcv",
+	"xpub661no6RGEX3uJkY4bNnPcw4URcQTrSibUZ4NqJEw5eBkv7ovTwgiT91XX27VbEXGENhYRCf7hyEbWrR3FewATdCEebj6znwMfQkhRYHRLpJ",
+	"xprv9s21ZrQH4r4TsiLvyLXqM9P7k1K3EYhA1kkD6xuquB5i39AU8KF42acDyL3qsDbU9NmZn6MsGSUYZEsuoePmjzsB3eFKSUEh3Gu1N3cqVUN",
+	"xpub661MyMwAuDcm6CRQ5N4qiHKrJ39Xe1R1NyfouMKTTWcguwVcfrZJaNvhpebzGerh7gucBvzEQWRugZDuDXjNDRmXzSZe4c7mnTK97pTvGS8",
+	"DMwo58pR1QLEFihHiXPVykYB6fJmsTeHvyTp7hRThAtCX8CvYzgPcn8XnmdfHGMQzT7ayAmfo4z3gY5KfbrZWZ6St24UVf2Qgo6oujFktLHdHY4",
+	"DMwo58pR1QLEFihHiXPVykYB6fJmsTeHvyTp7hRThAtCX8CvYzgPcn8XnmdfHPmHJiEDXkTiJTVV9rHEBUem2mwVbbNfvT2MTcAqj3nesx8uBf9",
+	"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzF93Y5wvzdUayhgkkFoicQZcP3y52uPPxFnfoLZB21Teqt1VvEHx",
+	"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFAzHGBP2UuGCqWLTAPLcMtD5SDKr24z3aiUvKr9bJpdrcLg1y3G",
+	"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHL",
+	"xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6Q5JXayek4PRsn35jii4veMimro1xefsM58PgBMrvdYre8QyULY",
+}
+
+func TestNew_Bip32TestVectors(t *testing.T) {
+	for _, test := range bip32TestVectors {
+		seed, err := hex.DecodeString(test.seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		key, err := New(&Config{Seed: seed, Network: NetworkTypeMainnet, DerivationPath: test.derivationPath, AddrType: AddrTypeLegacy})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.XPub != test.xPub {
+			t.Fatal("expected", test.xPub, ", got", key.XPub, ", for", test.derivationPath)
+		}
+
+		if key.XPrv != test.xPrv {
+			t.Fatal("expected", test.xPrv, ", got", key.XPrv, ", for", test.derivationPath)
+		}
+	}
+}
+
+// TestDerive_Bip32TestVectors derives keys from master private key of each
+// test vector and checks that public keys can be derived from parent public
+// keys along unhardened paths
+func TestDerive_Bip32TestVectors(t *testing.T) {
+	masterKeys := make(map[string]string)
+	for _, test := range bip32TestVectors {
+		if test.derivationPath == "m" {
+			masterKeys[test.seed] = test.xPrv
+		}
+	}
+
+	for _, test := range bip32TestVectors {
+		key, err := Derive(masterKeys[test.seed], test.derivationPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.XPub != test.xPub {
+			t.Fatal("expected", test.xPub, ", got", key.XPub, ", for", test.derivationPath)
+		}
+
+		if key.XPrv != test.xPrv {
+			t.Fatal("expected", test.xPrv, ", got", key.XPrv, ", for", test.derivationPath)
+		}
+	}
+
+	// test vector 2 path m/0/2147483647H/1 has unhardened last index
+	parent := "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a"
+	expected := "xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon"
+	key, err := Derive(parent, "m/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key.XPub != expected {
+		t.Fatal("expected", expected, ", got", key.XPub)
+	}
+
+	if _, err := Derive(parent, "m/1h"); err == nil {
+		t.Fatal("expected error deriving hardened child from public key")
+	}
+}
+
+func TestValidate_Bip32TestVectors(t *testing.T) {
+	for _, test := range bip32TestVectors {
+		for _, key := range []string{test.xPub, test.xPrv} {
+			if err := Validate(key); err != nil {
+				t.Fatal(err, ", for", key)
+			}
+		}
+	}
+
+	for _, key := range bip32InvalidKeys {
+		if err := Validate(key); err == nil {
+			t.Fatal("expected validation error for", key)
+		}
+	}
+}
+
+// TestNew_Fixtures checks keys generated for address matrix in
+// test folder using path,addr,pubKeyHex,wif csv layout. Fixtures were
+// validated using https://iancoleman.io/bip39/#english
+func TestNew_Fixtures(t *testing.T) {
+	seed := seeds.New("clown tragic float hat law oven crew figure salt push analyst script", "")
+
+	tests := []struct {
+		filename string
+		network  string
+		addrType string
+	}{
+		{filename: "bip32.csv", network: NetworkTypeMainnet, addrType: AddrTypeLegacy},
+		{filename: "bip44.csv", network: NetworkTypeMainnet, addrType: AddrTypeLegacy},
+		{filename: "bip49.csv", network: NetworkTypeMainnet, addrType: AddrTypeSegWitCompatible},
+		{filename: "bip84.csv", network: NetworkTypeMainnet, addrType: AddrTypeSegWitNative},
+		{filename: "bip32-testnet.csv", network: NetworkTypeTestnet, addrType: AddrTypeLegacy},
+		{filename: "bip44-testnet.csv", network: NetworkTypeTestnet, addrType: AddrTypeLegacy},
+		{filename: "bip49-testnet.csv", network: NetworkTypeTestnet, addrType: AddrTypeSegWitCompatible},
+		{filename: "bip84-testnet.csv", network: NetworkTypeTestnet, addrType: AddrTypeSegWitNative},
+	}
+
+	for _, test := range tests {
+		f, err := os.Open(filepath.Join("..", "..", "test", test.filename))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := csv.NewReader(f).ReadAll()
+		_ = f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(records) == 0 {
+			t.Fatal("no records found in", test.filename)
+		}
+
+		for _, record := range records {
+			derivationPath, addr, pubKeyHex, prvKeyWif := record[0], record[1], record[2], record[3]
+
+			key, err := New(&Config{Seed: seed, Network: test.network, DerivationPath: derivationPath, AddrType: test.addrType})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if key.Addr != addr {
+				t.Fatal("expected", addr, ", got", key.Addr, ", for", derivationPath, "in", test.filename)
+			}
+
+			if key.PubKeyHex != pubKeyHex {
+				t.Fatal("expected", pubKeyHex, ", got", key.PubKeyHex, ", for", derivationPath, "in", test.filename)
+			}
+
+			if key.PrvKeyWif != prvKeyWif {
+				t.Fatal("expected", prvKeyWif, ", got", key.PrvKeyWif, ", for", derivationPath, "in", test.filename)
+			}
+		}
+	}
+}
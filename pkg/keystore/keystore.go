@@ -0,0 +1,371 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// entry types
+const (
+	TypeSeed = "seed"
+	TypeXPrv = "xprv"
+)
+
+// ciphers for encrypting entry secrets
+const (
+	CipherAesGcm            = "aes-256-gcm"
+	CipherXChaCha20Poly1305 = "xchacha20-poly1305"
+)
+
+// scrypt parameters for new entries, which are stored
+// along with each entry for decryption
+const (
+	kdfScrypt = "scrypt"
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	keyLen    = 32
+	saltLen   = 32
+)
+
+const (
+	fileVersion = 1
+	fileExt     = ".json"
+)
+
+// namePattern restricts entry names to chars safe for file names
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+// Entry describes a keystore entry without its secret
+type Entry struct {
+	Name      string `json:"name" yaml:"name"`
+	Type      string `json:"type" yaml:"type"`
+	Cipher    string `json:"cipher" yaml:"cipher"`
+	CreatedAt string `json:"createdAt" yaml:"createdAt"`
+}
+
+// Secret is a decrypted keystore entry. Seed is hex encoded
+type Secret struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+	Seed string `json:"seed,omitempty" yaml:"seed,omitempty"`
+	XPrv string `json:"xPrv,omitempty" yaml:"xPrv,omitempty"`
+}
+
+// entryFile is the serialized form of an entry on disk
+type entryFile struct {
+	Version int `json:"version"`
+	Entry
+	Kdf        string    `json:"kdf"`
+	KdfParams  kdfParams `json:"kdfParams"`
+	Nonce      string    `json:"nonce"`
+	CipherText string    `json:"cipherText"`
+}
+
+type kdfParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+// Store keeps entries as passphrase encrypted files in a directory
+type Store struct {
+	dir string
+}
+
+// DefaultDir returns keystore directory under home directory
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home dir: %w", err)
+	}
+
+	return filepath.Join(home, ".bip32", "keystore"), nil
+}
+
+// New returns keystore using the directory, which defaults to
+// DefaultDir when empty. Directory is created when entries are added
+func New(dir string) (*Store, error) {
+	if len(dir) == 0 {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// Add encrypts the secret with the passphrase and saves it under the name.
+// Secret is raw seed for seed entries and base58 encoded key for xprv entries
+func (s *Store) Add(name, entryType string, secret []byte, passphrase, cipherName string) (*Entry, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	if entryType != TypeSeed && entryType != TypeXPrv {
+		return nil, fmt.Errorf("invalid entry type: %s. allowed types are %v", entryType, []string{TypeSeed, TypeXPrv})
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := newAead(cipherName, passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	entry := Entry{
+		Name:      name,
+		Type:      entryType,
+		Cipher:    cipherName,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	f := &entryFile{
+		Version: fileVersion,
+		Entry:   entry,
+		Kdf:     kdfScrypt,
+		KdfParams: kdfParams{
+			N:    scryptN,
+			R:    scryptR,
+			P:    scryptP,
+			Salt: hex.EncodeToString(salt),
+		},
+		Nonce:      hex.EncodeToString(nonce),
+		CipherText: hex.EncodeToString(aead.Seal(nil, nonce, secret, additionalData(&entry))),
+	}
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize entry: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore dir: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("entry %s already exists", name)
+		}
+		return nil, fmt.Errorf("failed to create entry file: %w", err)
+	}
+
+	if _, err := file.Write(b); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to write entry file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close entry file: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// List returns entries sorted by name
+func (s *Store) List() ([]*Entry, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []*Entry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read keystore dir: %w", err)
+	}
+
+	entries := make([]*Entry, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), fileExt) {
+			continue
+		}
+
+		f, err := s.read(strings.TrimSuffix(file.Name(), fileExt))
+		if err != nil {
+			return nil, err
+		}
+
+		entry := f.Entry
+		entries = append(entries, &entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	return entries, nil
+}
+
+// Remove deletes the entry
+func (s *Store) Remove(name string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.path(name)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("entry %s not found", name)
+		}
+		return fmt.Errorf("failed to remove entry file: %w", err)
+	}
+
+	return nil
+}
+
+// Export decrypts the entry using the passphrase
+func (s *Store) Export(name, passphrase string) (*Secret, error) {
+	f, err := s.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Version != fileVersion || f.Kdf != kdfScrypt {
+		return nil, fmt.Errorf("unsupported entry version %d or kdf %s", f.Version, f.Kdf)
+	}
+
+	salt, err := hex.DecodeString(f.KdfParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode salt: %w", err)
+	}
+
+	nonce, err := hex.DecodeString(f.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode nonce: %w", err)
+	}
+
+	cipherText, err := hex.DecodeString(f.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cipher text: %w", err)
+	}
+
+	aead, err := newAead(f.Cipher, passphrase, salt, f.KdfParams.N, f.KdfParams.R, f.KdfParams.P)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+
+	secret, err := aead.Open(nil, nonce, cipherText, additionalData(&f.Entry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt entry %s, invalid passphrase or corrupt entry", name)
+	}
+
+	exported := &Secret{
+		Name: f.Name,
+		Type: f.Type,
+	}
+
+	switch f.Type {
+	case TypeSeed:
+		exported.Seed = hex.EncodeToString(secret)
+	case TypeXPrv:
+		exported.XPrv = string(secret)
+	default:
+		return nil, fmt.Errorf("invalid entry type: %s", f.Type)
+	}
+
+	return exported, nil
+}
+
+// read loads the entry file
+func (s *Store) read(name string) (*entryFile, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(s.path(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("entry %s not found", name)
+		}
+		return nil, fmt.Errorf("failed to read entry file: %w", err)
+	}
+
+	f := &entryFile{}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("failed to parse entry %s: %w", name, err)
+	}
+
+	if f.Name != name {
+		return nil, fmt.Errorf("entry file %s holds entry %s", name, f.Name)
+	}
+
+	return f, nil
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+fileExt)
+}
+
+// newAead derives key from passphrase using scrypt and returns cipher
+func newAead(cipherName, passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	if cipherName != CipherAesGcm && cipherName != CipherXChaCha20Poly1305 {
+		return nil, fmt.Errorf("invalid or unsupported cipher: %s. allowed ciphers are %v",
+			cipherName, []string{CipherAesGcm, CipherXChaCha20Poly1305})
+	}
+
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key from passphrase: %w", err)
+	}
+
+	if cipherName == CipherXChaCha20Poly1305 {
+		aead, err := chacha20poly1305.NewX(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher: %w", err)
+		}
+		return aead, nil
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return aead, nil
+}
+
+// additionalData binds entry metadata to the cipher text
+func additionalData(entry *Entry) []byte {
+	return []byte(strings.Join([]string{entry.Name, entry.Type, entry.Cipher}, ":"))
+}
+
+func validateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid entry name %q, allowed chars are letters, digits, '.', '_' and '-'", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keystore")
+
+	store, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Fatal("expected empty keystore, got", len(entries))
+	}
+
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	xPrv := "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+
+	if _, err := store.Add("wallet", TypeSeed, seed, "secret", CipherAesGcm); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Add("cold.xprv", TypeXPrv, []byte(xPrv), "secret", CipherXChaCha20Poly1305); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Add("wallet", TypeSeed, seed, "secret", CipherAesGcm); err == nil {
+		t.Fatal("expected error for duplicate entry")
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "wallet.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Fatal("expected file mode 0600, got", info.Mode().Perm())
+	}
+
+	entries, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].Name != "cold.xprv" || entries[1].Cipher != CipherAesGcm {
+		t.Fatal("unexpected entries", entries)
+	}
+
+	secret, err := store.Export("wallet", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret.Type != TypeSeed || secret.Seed != hex.EncodeToString(seed) {
+		t.Fatal("unexpected secret", secret)
+	}
+
+	secret, err = store.Export("cold.xprv", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret.Type != TypeXPrv || secret.XPrv != xPrv {
+		t.Fatal("unexpected secret", secret)
+	}
+
+	if _, err := store.Export("wallet", "wrong"); err == nil {
+		t.Fatal("expected error for invalid passphrase")
+	}
+
+	if err := store.Remove("wallet"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Export("wallet", "secret"); err == nil {
+		t.Fatal("expected error for removed entry")
+	}
+
+	if err := store.Remove("wallet"); err == nil {
+		t.Fatal("expected error for removed entry")
+	}
+}
+
+func TestStore_Invalid(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		entryType  string
+		passphrase string
+		cipher     string
+	}{
+		{name: "../wallet", entryType: TypeSeed, passphrase: "secret", cipher: CipherAesGcm},
+		{name: "", entryType: TypeSeed, passphrase: "secret", cipher: CipherAesGcm},
+		{name: "wallet", entryType: "mnemonic", passphrase: "secret", cipher: CipherAesGcm},
+		{name: "wallet", entryType: TypeSeed, passphrase: "", cipher: CipherAesGcm},
+		{name: "wallet", entryType: TypeSeed, passphrase: "secret", cipher: "aes-128-cbc"},
+	}
+
+	for _, test := range tests {
+		if _, err := store.Add(test.name, test.entryType, []byte{1, 2, 3}, test.passphrase, test.cipher); err == nil {
+			t.Fatal("expected error for", test)
+		}
+	}
+}
+
+func TestStore_TamperedEntry(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Add("wallet", TypeSeed, []byte{1, 2, 3}, "secret", CipherAesGcm); err != nil {
+		t.Fatal(err)
+	}
+
+	// entry type is authenticated along with the cipher text
+	path := filepath.Join(dir, "wallet.json")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), `"type": "seed"`) {
+		t.Fatal("expected entry type in entry file")
+	}
+
+	b = []byte(strings.Replace(string(b), `"type": "seed"`, `"type": "xprv"`, 1))
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Export("wallet", "secret"); err == nil {
+		t.Fatal("expected error for tampered entry")
+	}
+}
@@ -0,0 +1,209 @@
+package psbt
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/kubetrail/bip32/pkg/keys"
+)
+
+// script types that do not have an address type counterpart in keys pkg
+const (
+	ScriptTypeP2pkh       = "p2pkh"
+	ScriptTypeP2sh        = "p2sh"
+	ScriptTypeP2pk        = "p2pk"
+	ScriptTypeMultisig    = "multisig"
+	ScriptTypeNullData    = "nulldata"
+	ScriptTypeNonStandard = "nonstandard"
+)
+
+// Summary describes psbt inputs and outputs for review before signing
+type Summary struct {
+	Txid     string           `json:"txid" yaml:"txid"`
+	Version  int32            `json:"version" yaml:"version"`
+	LockTime uint32           `json:"lockTime" yaml:"lockTime"`
+	Network  string           `json:"network" yaml:"network"`
+	Inputs   []*InputSummary  `json:"inputs" yaml:"inputs"`
+	Outputs  []*OutputSummary `json:"outputs" yaml:"outputs"`
+	Fee      string           `json:"fee,omitempty" yaml:"fee,omitempty"`
+	FeeSat   *int64           `json:"feeSat,omitempty" yaml:"feeSat,omitempty"`
+}
+
+// InputSummary describes an input and its ownership. Amount, address
+// and script are empty if the utxo being spent is not part of the psbt
+type InputSummary struct {
+	Index          int    `json:"index" yaml:"index"`
+	PrevOut        string `json:"prevOut" yaml:"prevOut"`
+	Amount         string `json:"amount,omitempty" yaml:"amount,omitempty"`
+	AmountSat      *int64 `json:"amountSat,omitempty" yaml:"amountSat,omitempty"`
+	Addr           string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	ScriptType     string `json:"scriptType,omitempty" yaml:"scriptType,omitempty"`
+	Script         string `json:"script,omitempty" yaml:"script,omitempty"`
+	Owned          *bool  `json:"owned,omitempty" yaml:"owned,omitempty"`
+	DerivationPath string `json:"derivationPath,omitempty" yaml:"derivationPath,omitempty"`
+	Fingerprint    string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+	PartialSigs    int    `json:"partialSigs" yaml:"partialSigs"`
+	Finalized      bool   `json:"finalized" yaml:"finalized"`
+}
+
+// OutputSummary describes an output, its ownership and whether
+// it is a change output
+type OutputSummary struct {
+	Index          int    `json:"index" yaml:"index"`
+	Amount         string `json:"amount" yaml:"amount"`
+	AmountSat      int64  `json:"amountSat" yaml:"amountSat"`
+	Addr           string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	ScriptType     string `json:"scriptType" yaml:"scriptType"`
+	Script         string `json:"script" yaml:"script"`
+	Owned          *bool  `json:"owned,omitempty" yaml:"owned,omitempty"`
+	Change         *bool  `json:"change,omitempty" yaml:"change,omitempty"`
+	DerivationPath string `json:"derivationPath,omitempty" yaml:"derivationPath,omitempty"`
+	Fingerprint    string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+}
+
+// Inspect summarizes psbt inputs and outputs. Ownership is reported
+// only if owner is not nil, in which case addresses are encoded for
+// the owner network instead of the input network. An owned output is
+// change if the second last element of its derivation path is 1 per
+// BIP-44 change chain
+func (p *Packet) Inspect(owner *Owner, network string) (*Summary, error) {
+	if owner != nil {
+		network = owner.Network()
+	}
+
+	summary := &Summary{
+		Txid:     p.UnsignedTx.TxHash().String(),
+		Version:  p.UnsignedTx.Version,
+		LockTime: p.UnsignedTx.LockTime,
+		Network:  network,
+	}
+
+	var inputSat int64
+	inputAmountsKnown := true
+	for i, input := range p.Inputs {
+		s := &InputSummary{
+			Index:       i,
+			PrevOut:     p.UnsignedTx.TxIn[i].PreviousOutPoint.String(),
+			PartialSigs: len(input.PartialSigs),
+			Finalized:   input.FinalScriptSig != nil || input.FinalScriptWitness != nil,
+		}
+
+		if len(input.Bip32Derivation) > 0 {
+			s.DerivationPath = keys.FormatDerivationPath(input.Bip32Derivation[0].Bip32Path)
+			s.Fingerprint = hex.EncodeToString(fingerprintOf(input.Bip32Derivation[0]))
+		}
+
+		prevOut, err := p.PrevOut(i)
+		if err != nil {
+			inputAmountsKnown = false
+			summary.Inputs = append(summary.Inputs, s)
+			continue
+		}
+
+		amountSat := prevOut.Value
+		inputSat += amountSat
+
+		s.AmountSat = &amountSat
+		s.Amount = btcutil.Amount(amountSat).String()
+		s.Script = hex.EncodeToString(prevOut.PkScript)
+		s.ScriptType = scriptType(prevOut.PkScript, input.RedeemScript)
+		s.Addr, _ = keys.ScriptAddr(prevOut.PkScript, network)
+
+		if owner != nil {
+			d, err := owner.owns(input.Bip32Derivation, prevOut.PkScript, input.RedeemScript, input.WitnessScript)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check ownership of input %d: %w", i, err)
+			}
+
+			owned := d != nil
+			s.Owned = &owned
+			if owned {
+				s.DerivationPath = keys.FormatDerivationPath(d.Bip32Path)
+				s.Fingerprint = hex.EncodeToString(fingerprintOf(d))
+			}
+		}
+
+		summary.Inputs = append(summary.Inputs, s)
+	}
+
+	var outputSat int64
+	for i, txOut := range p.UnsignedTx.TxOut {
+		output := p.Outputs[i]
+		outputSat += txOut.Value
+
+		s := &OutputSummary{
+			Index:      i,
+			Amount:     btcutil.Amount(txOut.Value).String(),
+			AmountSat:  txOut.Value,
+			Script:     hex.EncodeToString(txOut.PkScript),
+			ScriptType: scriptType(txOut.PkScript, output.RedeemScript),
+		}
+		s.Addr, _ = keys.ScriptAddr(txOut.PkScript, network)
+
+		if len(output.Bip32Derivation) > 0 {
+			s.DerivationPath = keys.FormatDerivationPath(output.Bip32Derivation[0].Bip32Path)
+			s.Fingerprint = hex.EncodeToString(fingerprintOf(output.Bip32Derivation[0]))
+		}
+
+		if owner != nil {
+			d, err := owner.owns(output.Bip32Derivation, txOut.PkScript, output.RedeemScript, output.WitnessScript)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check ownership of output %d: %w", i, err)
+			}
+
+			owned, change := d != nil, false
+			if owned {
+				s.DerivationPath = keys.FormatDerivationPath(d.Bip32Path)
+				s.Fingerprint = hex.EncodeToString(fingerprintOf(d))
+				change = len(d.Bip32Path) >= 2 && d.Bip32Path[len(d.Bip32Path)-2] == 1
+			}
+			s.Owned, s.Change = &owned, &change
+		}
+
+		summary.Outputs = append(summary.Outputs, s)
+	}
+
+	if inputAmountsKnown {
+		feeSat := inputSat - outputSat
+		summary.FeeSat = &feeSat
+		summary.Fee = btcutil.Amount(feeSat).String()
+	}
+
+	return summary, nil
+}
+
+// scriptType returns type of the output script, where nested
+// segwit types are identified using the redeem script
+func scriptType(pkScript, redeemScript []byte) string {
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyHashTy:
+		return ScriptTypeP2pkh
+	case txscript.WitnessV0PubKeyHashTy:
+		return keys.AddrTypeP2wpkh
+	case txscript.WitnessV0ScriptHashTy:
+		return keys.AddrTypeP2wsh
+	case txscript.ScriptHashTy:
+		switch {
+		case txscript.IsPayToWitnessPubKeyHash(redeemScript):
+			return keys.AddrTypeP2wpkhP2sh
+		case txscript.IsPayToWitnessScriptHash(redeemScript):
+			return keys.AddrTypeP2wshP2sh
+		default:
+			return ScriptTypeP2sh
+		}
+	case txscript.PubKeyTy:
+		return ScriptTypeP2pk
+	case txscript.MultiSigTy:
+		return ScriptTypeMultisig
+	case txscript.NullDataTy:
+		return ScriptTypeNullData
+	}
+
+	if len(pkScript) == 34 && pkScript[0] == txscript.OP_1 && pkScript[1] == txscript.OP_DATA_32 {
+		return keys.AddrTypeP2tr
+	}
+
+	return ScriptTypeNonStandard
+}
@@ -0,0 +1,159 @@
+package psbt
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+func TestPacket_Inspect(t *testing.T) {
+	p, pkScript, amount := newTestPacket(t, keys.AddrTypeBip84, "m/84h/0h/0h/0/1")
+
+	change, err := keys.New(&keys.Config{
+		Seed:           seeds.New(testMnemonic, ""),
+		Network:        keys.NetworkTypeMainnet,
+		DerivationPath: "m/84h/0h/0h/1/0",
+		AddrType:       keys.AddrTypeBip84,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changePubKey, err := hex.DecodeString(change.PubKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint, err := hex.DecodeString(change.MasterFingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changePath, err := keys.ParseDerivationPath(change.DerivationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// send part of the amount to self as change
+	changeScript := append([]byte{0x00, 0x14}, btcutil.Hash160(changePubKey)...)
+	p.UnsignedTx.TxOut[0].Value = amount / 2
+	p.UnsignedTx.AddTxOut(wire.NewTxOut(amount/2-500, changeScript))
+	p.Outputs = append(p.Outputs, Output{
+		Bip32Derivation: []*Bip32Derivation{
+			{
+				PubKey:               changePubKey,
+				MasterKeyFingerprint: binary.LittleEndian.Uint32(fingerprint),
+				Bip32Path:            changePath,
+			},
+		},
+	})
+
+	// output 0 pays to the same script as input without derivation
+	// info, so it is treated as external
+	account, err := keys.New(&keys.Config{
+		Seed:           seeds.New(testMnemonic, ""),
+		Network:        keys.NetworkTypeMainnet,
+		DerivationPath: "m/84h/0h/0h",
+		AddrType:       keys.AddrTypeBip84,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner, err := NewOwner(account.XPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := p.Inspect(owner, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.FeeSat == nil || *summary.FeeSat != 500 {
+		t.Fatal("expected fee of 500 sat, got", summary.Fee)
+	}
+
+	input := summary.Inputs[0]
+	if input.Owned == nil || !*input.Owned {
+		t.Fatal("expected input to be owned")
+	}
+
+	if input.ScriptType != keys.AddrTypeP2wpkh || input.Script != hex.EncodeToString(pkScript) {
+		t.Fatal("unexpected input script", input.ScriptType, input.Script)
+	}
+
+	if expected := "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g"; input.Addr != expected {
+		t.Fatal("expected", expected, ", got", input.Addr)
+	}
+
+	if input.DerivationPath != "m/84h/0h/0h/0/1" {
+		t.Fatal("unexpected input derivation path", input.DerivationPath)
+	}
+
+	if output := summary.Outputs[0]; *output.Owned || *output.Change {
+		t.Fatal("expected output 0 to be external")
+	}
+
+	if output := summary.Outputs[1]; !*output.Owned || !*output.Change {
+		t.Fatal("expected output 1 to be owned change")
+	}
+
+	// master public key cannot derive hardened paths and
+	// foreign keys do not match fingerprints
+	master, err := keys.New(&keys.Config{
+		Seed:           seeds.New(testMnemonic, ""),
+		Network:        keys.NetworkTypeMainnet,
+		DerivationPath: "m",
+		AddrType:       keys.AddrTypeBip84,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foreign, err := keys.New(&keys.Config{
+		Seed:           seeds.New("zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong", ""),
+		Network:        keys.NetworkTypeMainnet,
+		DerivationPath: "m/84h/0h/0h",
+		AddrType:       keys.AddrTypeBip84,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, xKey := range []string{master.XPub, foreign.XPub} {
+		owner, err := NewOwner(xKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		summary, err := p.Inspect(owner, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if *summary.Inputs[0].Owned || *summary.Outputs[1].Owned {
+			t.Fatal("expected no ownership for", xKey)
+		}
+	}
+
+	// master private key can derive hardened paths
+	owner, err = NewOwner(master.XPrv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err = p.Inspect(owner, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !*summary.Inputs[0].Owned || !*summary.Outputs[1].Change {
+		t.Fatal("expected ownership for master private key")
+	}
+}
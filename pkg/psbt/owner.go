@@ -0,0 +1,163 @@
+package psbt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/tyler-smith/go-bip32"
+)
+
+// Owner checks whether BIP-32 derivation entries belong to an extended
+// key, which can be either private or public for watch-only checks
+type Owner struct {
+	xKey        string
+	network     string
+	fingerprint []byte
+	depth       int
+	childIndex  uint32
+	isPrivate   bool
+}
+
+// NewOwner returns an owner for the extended key. Derivation entries
+// match if their fingerprint is that of the extended key itself, or if
+// their path passes through the extended key at its depth
+func NewOwner(xKey string) (*Owner, error) {
+	key, err := keys.DecodeExtendedKey(xKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode extended key: %w", err)
+	}
+
+	fingerprint, err := hex.DecodeString(key.Fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key fingerprint: %w", err)
+	}
+
+	o := &Owner{
+		xKey:        xKey,
+		network:     key.Network,
+		fingerprint: fingerprint,
+		isPrivate:   len(key.XPrv) > 0,
+	}
+
+	if key.Depth != nil && *key.Depth > 0 {
+		indices, err := keys.ParseDerivationPath("m/" + key.ChildIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse child index: %w", err)
+		}
+
+		o.depth = int(*key.Depth)
+		o.childIndex = indices[0]
+	}
+
+	return o, nil
+}
+
+// Fingerprint returns hex encoded fingerprint of the owner key
+func (o *Owner) Fingerprint() string {
+	return hex.EncodeToString(o.fingerprint)
+}
+
+// Network returns network of the owner key
+func (o *Owner) Network() string {
+	return o.network
+}
+
+// derive returns the key for the derivation entry or nil if the entry
+// does not belong to the owner. Entries that require hardened derivation
+// from a public key are treated as not belonging to the owner
+func (o *Owner) derive(d *Bip32Derivation) (*keys.Key, error) {
+	var indices []uint32
+	switch {
+	case bytes.Equal(fingerprintOf(d), o.fingerprint):
+		indices = d.Bip32Path
+	case o.depth > 0 && len(d.Bip32Path) >= o.depth && d.Bip32Path[o.depth-1] == o.childIndex:
+		indices = d.Bip32Path[o.depth:]
+	default:
+		return nil, nil
+	}
+
+	if !o.isPrivate {
+		for _, idx := range indices {
+			if idx >= bip32.FirstHardenedChild {
+				return nil, nil
+			}
+		}
+	}
+
+	key, err := keys.Derive(o.xKey, keys.FormatDerivationPath(indices))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	pubKey, err := hex.DecodeString(key.PubKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode pub key: %w", err)
+	}
+
+	// entry pub key may be serialized uncompressed
+	entryPubKey, err := btcec.ParsePubKey(d.PubKey, btcec.S256())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pub key %x: %w", d.PubKey, err)
+	}
+
+	if !bytes.Equal(entryPubKey.SerializeCompressed(), pubKey) {
+		return nil, nil
+	}
+
+	return key, nil
+}
+
+// owns returns derivation entry that belongs to the owner and whose
+// pub key is committed to by the output script
+func (o *Owner) owns(derivations []*Bip32Derivation, pkScript, redeemScript, witnessScript []byte) (*Bip32Derivation, error) {
+	for _, d := range derivations {
+		key, err := o.derive(d)
+		if err != nil {
+			return nil, err
+		}
+
+		if key != nil && scriptCommitsTo(pkScript, redeemScript, witnessScript, d.PubKey) {
+			return d, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// scriptCommitsTo returns true if output script pays to the pub key either
+// directly, via p2wpkh nested in p2sh, or via redeem or witness script that
+// contains the pub key
+func scriptCommitsTo(pkScript, redeemScript, witnessScript, pubKey []byte) bool {
+	pubKeyHash := btcutil.Hash160(pubKey)
+
+	switch {
+	case txscript.GetScriptClass(pkScript) == txscript.PubKeyHashTy:
+		return bytes.Equal(pkScript[3:23], pubKeyHash)
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		return bytes.Equal(pkScript[2:], pubKeyHash)
+	case txscript.IsPayToWitnessScriptHash(pkScript):
+		witnessScriptHash := sha256.Sum256(witnessScript)
+		return len(witnessScript) > 0 &&
+			bytes.Equal(pkScript[2:], witnessScriptHash[:]) &&
+			bytes.Contains(witnessScript, pubKey)
+	case txscript.IsPayToScriptHash(pkScript):
+		if len(redeemScript) == 0 || !bytes.Equal(pkScript[2:22], btcutil.Hash160(redeemScript)) {
+			return false
+		}
+
+		if txscript.IsPayToScriptHash(redeemScript) {
+			return false
+		}
+
+		return scriptCommitsTo(redeemScript, nil, witnessScript, pubKey) ||
+			bytes.Contains(redeemScript, pubKey)
+	default:
+		return false
+	}
+}
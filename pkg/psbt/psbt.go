@@ -0,0 +1,104 @@
+package psbt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil/psbt"
+)
+
+// magic is the prefix of serialized psbt per
+// https://github.com/bitcoin/bips/blob/master/bip-0174.mediawiki#specification
+var magic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// Input, Output, PartialSig and Bip32Derivation are psbt fields
+// as parsed by btcutil psbt pkg
+type (
+	Input           = psbt.PInput
+	Output          = psbt.POutput
+	PartialSig      = psbt.PartialSig
+	Bip32Derivation = psbt.Bip32Derivation
+)
+
+// Packet is a partially signed bitcoin transaction per BIP-174.
+// Parsing and serialization are delegated to btcutil psbt pkg
+type Packet struct {
+	*psbt.Packet
+}
+
+// NewPacket returns a psbt for the unsigned transaction
+// with empty input and output fields
+func NewPacket(tx *wire.MsgTx) (*Packet, error) {
+	p, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create psbt: %w", err)
+	}
+
+	return &Packet{Packet: p}, nil
+}
+
+// Decode parses psbt that is either raw binary or base64 encoded.
+// It returns true if input was base64 encoded
+func Decode(b []byte) (*Packet, bool, error) {
+	isBase64 := false
+	if !bytes.HasPrefix(b, magic) {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+		if err != nil {
+			return nil, false, fmt.Errorf("input is neither binary nor base64 encoded psbt: %w", err)
+		}
+		b, isBase64 = raw, true
+	}
+
+	p, err := psbt.NewFromRawBytes(bytes.NewReader(b), false)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse psbt: %w", err)
+	}
+
+	return &Packet{Packet: p}, isBase64, nil
+}
+
+// PrevOut returns the output spent by input at index idx using either
+// witness or non-witness utxo. Non-witness utxo is checked against the
+// outpoint being spent and, if both are present, the witness utxo
+func (p *Packet) PrevOut(idx int) (*wire.TxOut, error) {
+	if idx < 0 || idx >= len(p.Inputs) {
+		return nil, fmt.Errorf("invalid input index %d", idx)
+	}
+
+	input := &p.Inputs[idx]
+	outPoint := p.UnsignedTx.TxIn[idx].PreviousOutPoint
+	switch {
+	case input.NonWitnessUtxo != nil:
+		if input.NonWitnessUtxo.TxHash() != outPoint.Hash {
+			return nil, fmt.Errorf("non-witness utxo does not match outpoint %s", outPoint)
+		}
+
+		if int(outPoint.Index) >= len(input.NonWitnessUtxo.TxOut) {
+			return nil, fmt.Errorf("outpoint %s not found in non-witness utxo", outPoint)
+		}
+
+		prevOut := input.NonWitnessUtxo.TxOut[outPoint.Index]
+		if input.WitnessUtxo != nil && !psbt.TxOutsEqual(prevOut, input.WitnessUtxo) {
+			return nil, fmt.Errorf("witness utxo does not match non-witness utxo for input %d", idx)
+		}
+
+		return prevOut, nil
+	case input.WitnessUtxo != nil:
+		return input.WitnessUtxo, nil
+	default:
+		return nil, fmt.Errorf("utxo not found for input %d", idx)
+	}
+}
+
+// fingerprintOf returns master key fingerprint of the derivation
+// entry, which btcutil psbt pkg reads as little endian uint32
+func fingerprintOf(d *Bip32Derivation) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, d.MasterKeyFingerprint)
+
+	return b
+}
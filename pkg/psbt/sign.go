@@ -0,0 +1,259 @@
+package psbt
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/kubetrail/bip32/pkg/keys"
+)
+
+// sigHashTypes maps names of sighash types defined for legacy
+// and segwit v0 inputs to their values
+var sigHashTypes = map[string]txscript.SigHashType{
+	"ALL":                 txscript.SigHashAll,
+	"NONE":                txscript.SigHashNone,
+	"SINGLE":              txscript.SigHashSingle,
+	"ALL|ANYONECANPAY":    txscript.SigHashAll | txscript.SigHashAnyOneCanPay,
+	"NONE|ANYONECANPAY":   txscript.SigHashNone | txscript.SigHashAnyOneCanPay,
+	"SINGLE|ANYONECANPAY": txscript.SigHashSingle | txscript.SigHashAnyOneCanPay,
+}
+
+// SigHashNames returns sorted names of defined sighash types
+func SigHashNames() []string {
+	names := make([]string, 0, len(sigHashTypes))
+	for name := range sigHashTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// sigHashName returns name of the sighash type or its hex
+// value if the type is not defined
+func sigHashName(hashType txscript.SigHashType) string {
+	for name, t := range sigHashTypes {
+		if t == hashType {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("0x%x", uint32(hashType))
+}
+
+// Signer derives private keys for inputs whose BIP-32 derivation
+// entries match the extended private key it holds. Only SIGHASH_ALL
+// signatures are produced unless other sighash types are allowed
+type Signer struct {
+	owner          *Owner
+	allowedSigHash map[txscript.SigHashType]bool
+}
+
+// NewSigner returns a signer for the extended private key. Derivation
+// entries are matched as described in NewOwner
+func NewSigner(xPrv string) (*Signer, error) {
+	owner, err := NewOwner(xPrv)
+	if err != nil {
+		return nil, err
+	}
+
+	if !owner.isPrivate {
+		return nil, fmt.Errorf("extended private key is required for signing")
+	}
+
+	return &Signer{
+		owner:          owner,
+		allowedSigHash: map[txscript.SigHashType]bool{txscript.SigHashAll: true},
+	}, nil
+}
+
+// NewSeedSigner returns a signer for the master key generated from the seed
+func NewSeedSigner(seed []byte) (*Signer, error) {
+	key, err := keys.New(
+		&keys.Config{
+			Seed:           seed,
+			Network:        keys.NetworkTypeMainnet,
+			DerivationPath: "m",
+			AddrType:       keys.AddrTypeLegacy,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate master key: %w", err)
+	}
+
+	return NewSigner(key.XPrv)
+}
+
+// Fingerprint returns hex encoded fingerprint of the signer key
+func (s *Signer) Fingerprint() string {
+	return s.owner.Fingerprint()
+}
+
+// AllowSigHash allows signing inputs that request the named sighash type,
+// such as NONE or SINGLE|ANYONECANPAY. Such signatures do not commit to all
+// inputs or outputs, hence, allow others to change the transaction
+func (s *Signer) AllowSigHash(name string) error {
+	hashType, ok := sigHashTypes[strings.ToUpper(name)]
+	if !ok {
+		return fmt.Errorf("invalid sighash type: %s. allowed types are %v", name, SigHashNames())
+	}
+
+	s.allowedSigHash[hashType] = true
+
+	return nil
+}
+
+// sigHashType returns the sighash type requested by the input, which
+// defaults to SIGHASH_ALL. Undefined types and types not allowed
+// for the signer are rejected
+func (s *Signer) sigHashType(input *Input) (txscript.SigHashType, error) {
+	hashType := txscript.SigHashAll
+	if input.SighashType != 0 {
+		hashType = input.SighashType
+	}
+
+	name := sigHashName(hashType)
+	if _, ok := sigHashTypes[name]; !ok {
+		return 0, fmt.Errorf("undefined sighash type %s", name)
+	}
+
+	if !s.allowedSigHash[hashType] {
+		return 0, fmt.Errorf("sighash type %s is not allowed, only ALL is signed by default", name)
+	}
+
+	return hashType, nil
+}
+
+// privateKey returns the private key for the derivation entry
+// or nil if the entry does not belong to the signer
+func (s *Signer) privateKey(d *Bip32Derivation) (*btcec.PrivateKey, error) {
+	key, err := s.owner.derive(d)
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	wif, err := btcutil.DecodeWIF(key.PrvKeyWif)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode wif key: %w", err)
+	}
+
+	return wif.PrivKey, nil
+}
+
+// Sign adds partial signatures to P2PKH, P2SH-P2WPKH and P2WPKH inputs
+// for which the signer can derive private keys and returns the number
+// of signatures added. Finalized inputs are skipped
+func (p *Packet) Sign(s *Signer) (int, error) {
+	sigHashes := txscript.NewTxSigHashes(p.UnsignedTx)
+
+	count := 0
+	for i := range p.Inputs {
+		input := &p.Inputs[i]
+		if input.FinalScriptSig != nil || input.FinalScriptWitness != nil {
+			continue
+		}
+
+		for _, derivation := range input.Bip32Derivation {
+			if hasPartialSig(input, derivation.PubKey) {
+				continue
+			}
+
+			privKey, err := s.privateKey(derivation)
+			if err != nil {
+				return count, fmt.Errorf("failed to get private key for input %d: %w", i, err)
+			}
+
+			if privKey == nil {
+				continue
+			}
+
+			hashType, err := s.sigHashType(input)
+			if err != nil {
+				return count, fmt.Errorf("failed to sign input %d: %w", i, err)
+			}
+
+			sig, err := p.signInput(i, derivation.PubKey, privKey, hashType, sigHashes)
+			if err != nil {
+				return count, fmt.Errorf("failed to sign input %d: %w", i, err)
+			}
+
+			input.PartialSigs = append(input.PartialSigs, &PartialSig{PubKey: derivation.PubKey, Signature: sig})
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// signInput returns signature with sighash type appended for input
+// at index idx after checking that the pub key is committed to by
+// the script being spent
+func (p *Packet) signInput(idx int, pubKey []byte, privKey *btcec.PrivateKey, hashType txscript.SigHashType, sigHashes *txscript.TxSigHashes) ([]byte, error) {
+	input := &p.Inputs[idx]
+
+	// witness utxo alone does not commit to the amounts of other
+	// inputs, which allows overstating fee across signing sessions,
+	// therefore, the full previous transaction is always required
+	if input.NonWitnessUtxo == nil {
+		return nil, fmt.Errorf("non-witness utxo is required for signing")
+	}
+
+	prevOut, err := p.PrevOut(idx)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKeyHash := btcutil.Hash160(pubKey)
+	pkScript := prevOut.PkScript
+
+	switch {
+	case txscript.GetScriptClass(pkScript) == txscript.PubKeyHashTy:
+		if !bytes.Equal(pkScript[3:23], pubKeyHash) {
+			return nil, fmt.Errorf("p2pkh script does not match pub key %x", pubKey)
+		}
+
+		return txscript.RawTxInSignature(p.UnsignedTx, idx, pkScript, hashType, privKey)
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		if !bytes.Equal(pkScript[2:], pubKeyHash) {
+			return nil, fmt.Errorf("p2wpkh script does not match pub key %x", pubKey)
+		}
+
+		return txscript.RawTxInWitnessSignature(p.UnsignedTx, sigHashes, idx, prevOut.Value, pkScript, hashType, privKey)
+	case txscript.IsPayToScriptHash(pkScript):
+		if input.RedeemScript == nil {
+			return nil, fmt.Errorf("redeem script is required for p2sh input")
+		}
+
+		if !bytes.Equal(pkScript[2:22], btcutil.Hash160(input.RedeemScript)) {
+			return nil, fmt.Errorf("redeem script does not match p2sh script")
+		}
+
+		if !txscript.IsPayToWitnessPubKeyHash(input.RedeemScript) {
+			return nil, fmt.Errorf("unsupported redeem script, only p2wpkh nested in p2sh is supported")
+		}
+
+		if !bytes.Equal(input.RedeemScript[2:], pubKeyHash) {
+			return nil, fmt.Errorf("p2wpkh redeem script does not match pub key %x", pubKey)
+		}
+
+		return txscript.RawTxInWitnessSignature(p.UnsignedTx, sigHashes, idx, prevOut.Value, input.RedeemScript, hashType, privKey)
+	default:
+		return nil, fmt.Errorf("unsupported script type %s", txscript.GetScriptClass(pkScript))
+	}
+}
+
+// hasPartialSig returns true if input has a signature for the pub key
+func hasPartialSig(input *Input, pubKey []byte) bool {
+	for _, sig := range input.PartialSigs {
+		if bytes.Equal(sig.PubKey, pubKey) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,344 @@
+package psbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip39/pkg/seeds"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// newTestPacket returns a psbt spending an output paid to the key
+// generated for the address type and derivation path
+func newTestPacket(t *testing.T, addrType, derivationPath string) (*Packet, []byte, int64) {
+	key, err := keys.New(&keys.Config{
+		Seed:           seeds.New(testMnemonic, ""),
+		Network:        keys.NetworkTypeMainnet,
+		DerivationPath: derivationPath,
+		AddrType:       addrType,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := btcutil.DecodeAddress(key.Addr, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	amount := int64(100000)
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	prevTx.AddTxOut(wire.NewTxOut(amount, pkScript))
+
+	prevHash := prevTx.TxHash()
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(amount-1000, pkScript))
+
+	pubKey, err := hex.DecodeString(key.PubKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint, err := hex.DecodeString(key.MasterFingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := keys.ParseDerivationPath(key.DerivationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPacket(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := &p.Inputs[0]
+	input.NonWitnessUtxo = prevTx
+	input.Bip32Derivation = []*Bip32Derivation{
+		{
+			PubKey:               pubKey,
+			MasterKeyFingerprint: binary.LittleEndian.Uint32(fingerprint),
+			Bip32Path:            path,
+		},
+	}
+
+	if txscript.IsPayToScriptHash(pkScript) {
+		input.RedeemScript = append([]byte{txscript.OP_0, txscript.OP_DATA_20}, btcutil.Hash160(pubKey)...)
+	}
+
+	return p, pkScript, amount
+}
+
+// verifyPacket finalizes the only input of the psbt and executes the scripts
+func verifyPacket(t *testing.T, p *Packet, pkScript []byte, amount int64) {
+	if len(p.Inputs[0].PartialSigs) != 1 {
+		t.Fatal("expected one partial sig, got", len(p.Inputs[0].PartialSigs))
+	}
+
+	sig := p.Inputs[0].PartialSigs[0]
+	tx := p.UnsignedTx.Copy()
+
+	switch {
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		tx.TxIn[0].Witness = wire.TxWitness{sig.Signature, sig.PubKey}
+	case txscript.IsPayToScriptHash(pkScript):
+		sigScript, err := txscript.NewScriptBuilder().AddData(p.Inputs[0].RedeemScript).Script()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.TxIn[0].SignatureScript = sigScript
+		tx.TxIn[0].Witness = wire.TxWitness{sig.Signature, sig.PubKey}
+	default:
+		sigScript, err := txscript.NewScriptBuilder().AddData(sig.Signature).AddData(sig.PubKey).Script()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.TxIn[0].SignatureScript = sigScript
+	}
+
+	engine, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil,
+		txscript.NewTxSigHashes(tx), amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPacket_Sign(t *testing.T) {
+	seedSigner, err := NewSeedSigner(seeds.New(testMnemonic, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		addrType       string
+		derivationPath string
+		accountPath    string
+	}{
+		{addrType: keys.AddrTypeBip44, derivationPath: "m/44h/0h/0h/0/3", accountPath: "m/44h/0h/0h"},
+		{addrType: keys.AddrTypeBip49, derivationPath: "m/49h/0h/0h/1/0", accountPath: "m/49h/0h/0h"},
+		{addrType: keys.AddrTypeBip84, derivationPath: "m/84h/0h/0h/0/7", accountPath: "m/84h/0h/0h"},
+	}
+
+	for _, test := range tests {
+		account, err := keys.New(&keys.Config{
+			Seed:           seeds.New(testMnemonic, ""),
+			Network:        keys.NetworkTypeMainnet,
+			DerivationPath: test.accountPath,
+			AddrType:       test.addrType,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		accountSigner, err := NewSigner(account.XPrv)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, signer := range []*Signer{seedSigner, accountSigner} {
+			p, pkScript, amount := newTestPacket(t, test.addrType, test.derivationPath)
+
+			// roundtrip via base64 encoding
+			b64, err := p.B64Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, isBase64, err := Decode([]byte(b64))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !isBase64 {
+				t.Fatal("expected base64 encoded input to be detected")
+			}
+
+			n, err := p.Sign(signer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if n != 1 {
+				t.Fatal("expected one signature, got", n, ", for", test.derivationPath)
+			}
+
+			// signing again should not add signatures
+			if n, err := p.Sign(signer); err != nil || n != 0 {
+				t.Fatal("expected no new signatures, got", n, err)
+			}
+
+			var b bytes.Buffer
+			if err := p.Serialize(&b); err != nil {
+				t.Fatal(err)
+			}
+
+			p, isBase64, err = Decode(b.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if isBase64 {
+				t.Fatal("expected binary input to be detected")
+			}
+
+			verifyPacket(t, p, pkScript, amount)
+		}
+	}
+}
+
+func TestPacket_SignForeignKey(t *testing.T) {
+	p, _, _ := newTestPacket(t, keys.AddrTypeBip84, "m/84h/0h/0h/0/0")
+
+	signer, err := NewSeedSigner(seeds.New("zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := p.Sign(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 0 {
+		t.Fatal("expected no signatures, got", n)
+	}
+}
+
+// TestPacket_SignNonWitnessUtxo checks that segwit inputs are only signed
+// when the previous transaction is present and matches the outpoint
+func TestPacket_SignNonWitnessUtxo(t *testing.T) {
+	signer, err := NewSeedSigner(seeds.New(testMnemonic, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, addrType := range []string{keys.AddrTypeBip49, keys.AddrTypeBip84} {
+		derivationPath := "m/49h/0h/0h/0/0"
+		if addrType == keys.AddrTypeBip84 {
+			derivationPath = "m/84h/0h/0h/0/0"
+		}
+
+		// witness utxo only
+		p, _, _ := newTestPacket(t, addrType, derivationPath)
+		prevTx := p.Inputs[0].NonWitnessUtxo
+		p.Inputs[0].WitnessUtxo = prevTx.TxOut[0]
+		p.Inputs[0].NonWitnessUtxo = nil
+		if _, err := p.Sign(signer); err == nil {
+			t.Fatal("expected error for missing non-witness utxo for", addrType)
+		}
+
+		// non-witness utxo of another transaction
+		p, _, _ = newTestPacket(t, addrType, derivationPath)
+		p.Inputs[0].NonWitnessUtxo.LockTime++
+		if _, err := p.Sign(signer); err == nil {
+			t.Fatal("expected error for non-witness utxo not matching outpoint for", addrType)
+		}
+
+		// witness utxo overstating amount
+		p, pkScript, amount := newTestPacket(t, addrType, derivationPath)
+		p.Inputs[0].WitnessUtxo = wire.NewTxOut(amount*2, pkScript)
+		if _, err := p.Sign(signer); err == nil {
+			t.Fatal("expected error for witness utxo not matching non-witness utxo for", addrType)
+		}
+
+		// both utxo fields matching
+		p, pkScript, amount = newTestPacket(t, addrType, derivationPath)
+		p.Inputs[0].WitnessUtxo = wire.NewTxOut(amount, pkScript)
+		if n, err := p.Sign(signer); err != nil || n != 1 {
+			t.Fatal("expected one signature, got", n, err)
+		}
+		verifyPacket(t, p, pkScript, amount)
+	}
+}
+
+// TestPacket_SignSigHash checks that only SIGHASH_ALL is signed by default,
+// other defined sighash types only when allowed and undefined types never
+func TestPacket_SignSigHash(t *testing.T) {
+	tests := []struct {
+		sighashType txscript.SigHashType
+		allow       []string
+		expectError bool
+	}{
+		{sighashType: 0},
+		{sighashType: txscript.SigHashAll},
+		{sighashType: txscript.SigHashNone, expectError: true},
+		{sighashType: txscript.SigHashSingle, expectError: true},
+		{sighashType: txscript.SigHashAll | txscript.SigHashAnyOneCanPay, expectError: true},
+		{sighashType: txscript.SigHashNone, allow: []string{"none"}},
+		{sighashType: txscript.SigHashSingle | txscript.SigHashAnyOneCanPay, allow: []string{"SINGLE|ANYONECANPAY"}},
+		{sighashType: txscript.SigHashSingle, allow: []string{"SINGLE|ANYONECANPAY"}, expectError: true},
+		{sighashType: 0x04, expectError: true},
+		{sighashType: 0x41, allow: SigHashNames(), expectError: true},
+		{sighashType: 0x101, allow: SigHashNames(), expectError: true},
+	}
+
+	for _, test := range tests {
+		signer, err := NewSeedSigner(seeds.New(testMnemonic, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range test.allow {
+			if err := signer.AllowSigHash(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		p, pkScript, amount := newTestPacket(t, keys.AddrTypeBip84, "m/84h/0h/0h/0/0")
+		p.Inputs[0].SighashType = test.sighashType
+
+		n, err := p.Sign(signer)
+		if test.expectError {
+			if err == nil || n != 0 || len(p.Inputs[0].PartialSigs) != 0 {
+				t.Fatal("expected error for sighash type", test.sighashType, test.allow)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		verifyPacket(t, p, pkScript, amount)
+
+		expected := byte(txscript.SigHashAll)
+		if test.sighashType != 0 {
+			expected = byte(test.sighashType)
+		}
+
+		if sig := p.Inputs[0].PartialSigs[0].Signature; sig[len(sig)-1] != expected {
+			t.Fatal("expected sighash byte", expected, ", got", sig[len(sig)-1])
+		}
+	}
+
+	signer, err := NewSeedSigner(seeds.New(testMnemonic, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := signer.AllowSigHash("anyonecanpay"); err == nil {
+		t.Fatal("expected error for invalid sighash type name")
+	}
+}
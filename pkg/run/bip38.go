@@ -0,0 +1,162 @@
+package run
+
+import (
+	"fmt"
+
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip39/pkg/passphrases"
+	"github.com/kubetrail/bip39/pkg/prompts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Bip38Encrypt(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.EcMultiply, cmd.Flag(flags.EcMultiply))
+	_ = viper.BindPFlag(flags.IntermediateCode, cmd.Flag(flags.IntermediateCode))
+	_ = viper.BindPFlag(flags.Lot, cmd.Flag(flags.Lot))
+	_ = viper.BindPFlag(flags.Sequence, cmd.Flag(flags.Sequence))
+	_ = viper.BindPFlag(flags.Network, cmd.Flag(flags.Network))
+	_ = viper.BindPFlag(flags.UncompressedWif, cmd.Flag(flags.UncompressedWif))
+
+	ecMultiply := viper.GetBool(flags.EcMultiply)
+	intermediateCode := viper.GetString(flags.IntermediateCode)
+	lot := viper.GetUint32(flags.Lot)
+	sequence := viper.GetUint32(flags.Sequence)
+	network := viper.GetString(flags.Network)
+	uncompressed := viper.GetBool(flags.UncompressedWif)
+
+	useLot := cmd.Flag(flags.Lot).Changed || cmd.Flag(flags.Sequence).Changed
+
+	// EC-multiplied keys are newly generated, hence
+	// private keys cannot be provided as args
+	if (ecMultiply || len(intermediateCode) > 0) && len(args) > 0 {
+		return fmt.Errorf("private key args cannot be used with --%s or --%s flags", flags.EcMultiply, flags.IntermediateCode)
+	}
+
+	if len(intermediateCode) > 0 {
+		key, err := keys.NewBip38Key(intermediateCode, network, !uncompressed)
+		if err != nil {
+			return fmt.Errorf("failed to generate encrypted key: %w", err)
+		}
+
+		return writeValue(cmd.OutOrStdout(), persistentFlags, key)
+	}
+
+	if ecMultiply {
+		passphrase, err := passphrases.New(cmd.OutOrStdout())
+		if err != nil {
+			return fmt.Errorf("failed to get passphrase: %w", err)
+		}
+
+		if useLot {
+			intermediateCode, err = keys.NewBip38IntermediateCodeWithLot(passphrase, lot, sequence)
+		} else {
+			intermediateCode, err = keys.NewBip38IntermediateCode(passphrase)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to generate intermediate code: %w", err)
+		}
+
+		key, err := keys.NewBip38Key(intermediateCode, network, !uncompressed)
+		if err != nil {
+			return fmt.Errorf("failed to generate encrypted key: %w", err)
+		}
+		key.IntermediateCode = intermediateCode
+
+		return writeValue(cmd.OutOrStdout(), persistentFlags, key)
+	}
+
+	keyString, err := readKeyString(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	decoded, err := keys.DecodePrivateWifKey(keyString)
+	if err != nil {
+		return fmt.Errorf("failed to decode private wif key: %w", err)
+	}
+
+	passphrase, err := passphrases.New(cmd.OutOrStdout())
+	if err != nil {
+		return fmt.Errorf("failed to get passphrase: %w", err)
+	}
+
+	encryptedKey, err := keys.EncryptBip38(keyString, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt private wif key: %w", err)
+	}
+
+	return writeValue(cmd.OutOrStdout(), persistentFlags, &keys.Bip38Key{
+		EncryptedKey: encryptedKey,
+		Addr:         decoded.Addr,
+		Network:      decoded.Network,
+	})
+}
+
+func Bip38Decrypt(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.Network, cmd.Flag(flags.Network))
+
+	network := viper.GetString(flags.Network)
+
+	keyString, err := readKeyString(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := passphrases.Prompt(cmd.OutOrStdout())
+	if err != nil {
+		return fmt.Errorf("failed to get passphrase: %w", err)
+	}
+
+	wif, err := keys.DecryptBip38(keyString, passphrase, network)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt key: %w", err)
+	}
+
+	key, err := keys.DecodePrivateWifKey(wif)
+	if err != nil {
+		return fmt.Errorf("failed to decode private wif key: %w", err)
+	}
+
+	w, err := newKeyWriter(cmd.OutOrStdout(), persistentFlags, false, decodeColumns)
+	if err != nil {
+		return err
+	}
+
+	if err := w.write(key); err != nil {
+		return err
+	}
+
+	return w.close()
+}
+
+// readKeyString reads key from args or from input
+// after prompting for it when input is a terminal
+func readKeyString(cmd *cobra.Command, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	prompt, err := prompts.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get prompt status: %w", err)
+	}
+
+	if prompt {
+		if err := keys.Prompt(cmd.OutOrStdout()); err != nil {
+			return "", fmt.Errorf("failed to prompt for key: %w", err)
+		}
+	}
+
+	keyString, err := keys.Read(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("failed to read key from input: %w", err)
+	}
+
+	return keyString, nil
+}
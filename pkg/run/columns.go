@@ -0,0 +1,45 @@
+package run
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/kubetrail/bip32/pkg/keys"
+)
+
+// column names for tabular output of keys, which are
+// the json field names of the key
+const (
+	columnDerivationPath = "derivationPath"
+	columnAddr           = "addr"
+	columnAddrType       = "addrType"
+	columnPubKeyHex      = "pubKeyHex"
+	columnPrvKeyWif      = "prvKeyWif"
+	columnNetwork        = "network"
+)
+
+// keyColumns are the columns available for keys, derived from json
+// field names of the key so that columns and jsonl output stay in step
+var keyColumns = jsonFieldNames(reflect.TypeOf(keys.Key{}))
+
+// default columns for each command
+var (
+	genColumns        = []string{columnDerivationPath, columnAddr, columnPrvKeyWif}
+	genAllColumns     = []string{columnDerivationPath, columnAddrType, columnAddr, columnPubKeyHex, columnPrvKeyWif}
+	deriveColumns     = []string{columnDerivationPath, columnAddrType, columnAddr, columnPubKeyHex}
+	decodeColumns     = []string{columnNetwork, columnAddrType, columnAddr, columnPubKeyHex}
+	descriptorColumns = []string{columnDerivationPath, columnAddrType, columnAddr, columnPubKeyHex}
+)
+
+// addressColumns are default columns for decoded addresses,
+// which are named after json fields of the address
+var addressColumns = []string{"addr", "network", "scriptType", "scriptPubKey"}
+
+// ColumnNames returns sorted list of columns available
+// for table, csv and jsonl output of keys
+func ColumnNames() []string {
+	names := append([]string{}, keyColumns...)
+	sort.Strings(names)
+
+	return names
+}
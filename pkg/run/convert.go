@@ -0,0 +1,54 @@
+package run
+
+import (
+	"fmt"
+
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip39/pkg/prompts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Convert(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.To, cmd.Flag(flags.To))
+
+	to := viper.GetString(flags.To)
+
+	prompt, err := prompts.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get prompt status: %w", err)
+	}
+
+	var keyString string
+
+	if len(args) == 0 {
+		if prompt {
+			if err := keys.Prompt(cmd.OutOrStdout()); err != nil {
+				return fmt.Errorf("failed to prompt for key: %w", err)
+			}
+		}
+
+		keyString, err = keys.Read(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("failed to read key from input: %w", err)
+		}
+	} else {
+		keyString = args[0]
+	}
+
+	converted, err := keys.Convert(keyString, to)
+	if err != nil {
+		return fmt.Errorf("failed to convert key: %w", err)
+	}
+
+	for _, warning := range converted.Warnings {
+		if _, err := fmt.Fprintln(cmd.ErrOrStderr(), "warning:", warning); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+
+	return writeValue(cmd.OutOrStdout(), persistentFlags, converted)
+}
@@ -2,7 +2,6 @@ package run
 
 import (
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 
 	"github.com/btcsuite/btcutil/base58"
@@ -10,11 +9,24 @@ import (
 	"github.com/kubetrail/bip32/pkg/keys"
 	"github.com/kubetrail/bip39/pkg/prompts"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
+	"github.com/spf13/viper"
+)
+
+// input formats decoded in addition to key formats
+const (
+	keyFormatCashAddr = "cashaddr"
+	keyFormatAddr     = "addr"
 )
 
 func Decode(cmd *cobra.Command, args []string) error {
+	_ = viper.BindPFlag(flags.Network, cmd.Flag(flags.Network))
+	_ = viper.BindPFlag(flags.AddrType, cmd.Flag(flags.AddrType))
+	_ = viper.BindPFlag(flags.Coin, cmd.Flag(flags.Coin))
+
 	persistentFlags := getPersistentFlags(cmd)
+	network := viper.GetString(flags.Network)
+	addrType := viper.GetString(flags.AddrType)
+	coin := viper.GetString(flags.Coin)
 
 	prompt, err := prompts.Status()
 	if err != nil {
@@ -39,8 +51,20 @@ func Decode(cmd *cobra.Command, args []string) error {
 	}
 
 	var keyFormat string
-	if keys.IsValidBase58String(keyString) {
+	if keys.IsCashAddr(keyString) {
+		keyFormat = keyFormatCashAddr
+	}
+
+	if len(keyFormat) == 0 && keys.IsSegWitAddr(keyString) {
+		keyFormat = keyFormatAddr
+	}
+
+	if len(keyFormat) == 0 && keys.IsValidBase58String(keyString) {
 		keyFormat = keys.KeyFormatB58
+		// p2pkh and p2sh addresses are 25 bytes long
+		if len(base58.Decode(keyString)) == 25 {
+			keyFormat = keyFormatAddr
+		}
 	}
 
 	if len(keyFormat) == 0 {
@@ -53,21 +77,33 @@ func Decode(cmd *cobra.Command, args []string) error {
 	switch keyFormat {
 	case keys.KeyFormatB58:
 		switch len(base58.Decode(keyString)) {
-		case 38: // treat input as a wif private key
+		case 37, 38: // treat input as a wif private key, uncompressed or compressed
 			key, err = keys.DecodePrivateWifKey(keyString)
 			if err != nil {
 				return fmt.Errorf("failed to decode private wif key: %w", err)
 			}
 		case 82: // treat input as extended key, private or public
-			key, err = keys.DecodeExtendedKey(keyString)
+			key, err = keys.DecodeExtendedKeyWithCoin(keyString, coin)
 			if err != nil {
 				return fmt.Errorf("failed to serialize key: %w", err)
 			}
 		default:
-			return fmt.Errorf("invalid input key length, needs to be either 38 bytes (prvKeyWif) or 82 bytes (xPrv, xPub) long")
+			return fmt.Errorf("invalid input key length, needs to be either 25 bytes (addr), 37 or 38 bytes (prvKeyWif) or 82 bytes (xPrv, xPub) long")
+		}
+	case keyFormatAddr:
+		address, err := keys.DecodeAddress(keyString)
+		if err != nil {
+			return fmt.Errorf("failed to decode address: %w", err)
+		}
+
+		return writeValueColumns(cmd.OutOrStdout(), persistentFlags, address, addressColumns)
+	case keyFormatCashAddr:
+		key, err = keys.DecodeCashAddr(keyString)
+		if err != nil {
+			return fmt.Errorf("failed to decode bitcoin cash address: %w", err)
 		}
 	case keys.KeyFormatHex:
-		key, err = keys.DecodePublicHex(keyString)
+		key, err = keys.DecodePublicHexWithNetwork(keyString, network, addrType)
 		if err != nil {
 			return fmt.Errorf("failed to decode public key hex: %w", err)
 		}
@@ -75,24 +111,14 @@ func Decode(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid base58 or hex key")
 	}
 
-	switch persistentFlags.OutputFormat {
-	case flags.OutputFormatNative, flags.OutputFormatYaml:
-		jb, err := yaml.Marshal(key)
-		if err != nil {
-			return fmt.Errorf("failed to serialize output to json: %w", err)
-		}
-		if _, err := fmt.Fprint(cmd.OutOrStdout(), string(jb)); err != nil {
-			return fmt.Errorf("failed to write to output: %w", err)
-		}
-	case flags.OutputFormatJson:
-		jb, err := json.Marshal(key)
-		if err != nil {
-			return fmt.Errorf("failed to serialize output to json: %w", err)
-		}
-		if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(jb)); err != nil {
-			return fmt.Errorf("failed to write to output: %w", err)
-		}
+	w, err := newKeyWriter(cmd.OutOrStdout(), persistentFlags, false, decodeColumns)
+	if err != nil {
+		return err
+	}
+
+	if err := w.write(key); err != nil {
+		return err
 	}
 
-	return nil
+	return w.close()
 }
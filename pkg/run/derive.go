@@ -1,22 +1,44 @@
 package run
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/kubetrail/bip32/pkg/flags"
 	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip32/pkg/keystore"
+	"github.com/kubetrail/bip39/pkg/passphrases"
 	"github.com/kubetrail/bip39/pkg/prompts"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
 )
 
 func Derive(cmd *cobra.Command, args []string) error {
 	persistentFlags := getPersistentFlags(cmd)
 
 	_ = viper.BindPFlag(flags.DerivationPath, cmd.Flag(flags.DerivationPath))
+	_ = viper.BindPFlag(flags.StartIndex, cmd.Flag(flags.StartIndex))
+	_ = viper.BindPFlag(flags.Count, cmd.Flag(flags.Count))
+	_ = viper.BindPFlag(flags.Network, cmd.Flag(flags.Network))
+	_ = viper.BindPFlag(flags.Coin, cmd.Flag(flags.Coin))
+	_ = viper.BindPFlag(flags.UncompressedWif, cmd.Flag(flags.UncompressedWif))
+	_ = viper.BindPFlag(flags.EncryptWif, cmd.Flag(flags.EncryptWif))
+	_ = viper.BindPFlag(flags.FromKeystore, cmd.Flag(flags.FromKeystore))
+	_ = viper.BindPFlag(flags.KeystoreDir, cmd.Flag(flags.KeystoreDir))
+
 	derivationPath := viper.GetString(flags.DerivationPath)
+	startIndex := viper.GetUint32(flags.StartIndex)
+	count := viper.GetUint32(flags.Count)
+	network := viper.GetString(flags.Network)
+	coin := viper.GetString(flags.Coin)
+	uncompressedWif := viper.GetBool(flags.UncompressedWif)
+	encryptWif := viper.GetBool(flags.EncryptWif)
+	fromKeystore := viper.GetString(flags.FromKeystore)
+	keystoreDir := viper.GetString(flags.KeystoreDir)
+
+	script, err := getScript(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get witness script: %w", err)
+	}
 
 	prompt, err := prompts.Status()
 	if err != nil {
@@ -25,7 +47,18 @@ func Derive(cmd *cobra.Command, args []string) error {
 
 	var keyString string
 
-	if len(args) == 0 {
+	if len(fromKeystore) > 0 {
+		if len(args) > 0 {
+			return fmt.Errorf("key arg cannot be used with --%s", flags.FromKeystore)
+		}
+
+		secret, err := readKeystoreSecret(cmd, keystoreDir, fromKeystore, keystore.TypeXPrv)
+		if err != nil {
+			return err
+		}
+
+		keyString = secret.XPrv
+	} else if len(args) == 0 {
 		if prompt {
 			if err := keys.Prompt(cmd.OutOrStdout()); err != nil {
 				return fmt.Errorf("failed to prompt for key: %w", err)
@@ -40,29 +73,53 @@ func Derive(cmd *cobra.Command, args []string) error {
 		keyString = args[0]
 	}
 
-	key, err := keys.Derive(keyString, derivationPath)
+	// encryption passphrase is read once for all generated keys
+	var passphrase string
+	if encryptWif {
+		passphrase, err = passphrases.New(cmd.OutOrStdout())
+		if err != nil {
+			return fmt.Errorf("failed to get encryption passphrase: %w", err)
+		}
+	}
+
+	rangePath := keys.IsRangeDerivationPath(derivationPath)
+	w, err := newKeyWriter(cmd.OutOrStdout(), persistentFlags, rangePath, deriveColumns)
 	if err != nil {
-		return fmt.Errorf("failed to derive key: %w", err)
+		return err
 	}
 
-	switch persistentFlags.OutputFormat {
-	case flags.OutputFormatNative, flags.OutputFormatYaml:
-		jb, err := yaml.Marshal(key)
-		if err != nil {
-			return fmt.Errorf("failed to serialize output to json: %w", err)
-		}
-		if _, err := fmt.Fprint(cmd.OutOrStdout(), string(jb)); err != nil {
-			return fmt.Errorf("failed to write to output: %w", err)
+	write := func(key *keys.Key) error {
+		if uncompressedWif {
+			if err := keys.UncompressKey(key); err != nil {
+				return fmt.Errorf("failed to uncompress key: %w", err)
+			}
 		}
-	case flags.OutputFormatJson:
-		jb, err := json.Marshal(key)
-		if err != nil {
-			return fmt.Errorf("failed to serialize output to json: %w", err)
+
+		if encryptWif {
+			if err := keys.EncryptKeyWif(key, passphrase); err != nil {
+				return err
+			}
 		}
-		if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(jb)); err != nil {
-			return fmt.Errorf("failed to write to output: %w", err)
+
+		return w.write(key)
+	}
+
+	if rangePath {
+		if err := keys.DeriveRange(keyString, derivationPath, coin, network, script, startIndex, count, write); err != nil {
+			return fmt.Errorf("failed to derive keys: %w", err)
 		}
+
+		return w.close()
+	}
+
+	key, err := keys.DeriveWithCoin(keyString, derivationPath, coin, network, script)
+	if err != nil {
+		return fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	if err := write(key); err != nil {
+		return err
 	}
 
-	return nil
+	return w.close()
 }
@@ -0,0 +1,70 @@
+package run
+
+import (
+	"fmt"
+
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip39/pkg/prompts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Descriptor(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.StartIndex, cmd.Flag(flags.StartIndex))
+	_ = viper.BindPFlag(flags.Count, cmd.Flag(flags.Count))
+
+	startIndex := viper.GetUint32(flags.StartIndex)
+	count := viper.GetUint32(flags.Count)
+
+	prompt, err := prompts.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get prompt status: %w", err)
+	}
+
+	var descString string
+
+	if len(args) == 0 {
+		if prompt {
+			if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Enter descriptor: "); err != nil {
+				return fmt.Errorf("failed to write to output: %w", err)
+			}
+		}
+
+		descString, err = keys.Read(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("failed to read descriptor from input: %w", err)
+		}
+	} else {
+		descString = args[0]
+	}
+
+	desc, err := keys.ParseDescriptor(descString)
+	if err != nil {
+		return fmt.Errorf("failed to parse descriptor: %w", err)
+	}
+
+	if !desc.IsRange() {
+		startIndex, count = 0, 1
+	}
+
+	w, err := newKeyWriter(cmd.OutOrStdout(), persistentFlags, true, descriptorColumns)
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		key, err := desc.Derive(startIndex + i)
+		if err != nil {
+			return fmt.Errorf("failed to derive address at index %d: %w", startIndex+i, err)
+		}
+
+		if err := w.write(key); err != nil {
+			return err
+		}
+	}
+
+	return w.close()
+}
@@ -1,19 +1,13 @@
 package run
 
 import (
-	"encoding/hex"
-	"encoding/json"
 	"fmt"
 
 	"github.com/kubetrail/bip32/pkg/flags"
 	"github.com/kubetrail/bip32/pkg/keys"
-	"github.com/kubetrail/bip39/pkg/mnemonics"
 	"github.com/kubetrail/bip39/pkg/passphrases"
-	"github.com/kubetrail/bip39/pkg/prompts"
-	"github.com/kubetrail/bip39/pkg/seeds"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
 )
 
 func Gen(cmd *cobra.Command, args []string) error {
@@ -26,7 +20,14 @@ func Gen(cmd *cobra.Command, args []string) error {
 	_ = viper.BindPFlag(flags.Network, cmd.Flag(flags.Network))
 	_ = viper.BindPFlag(flags.MnemonicLanguage, cmd.Flag(flags.MnemonicLanguage))
 	_ = viper.BindPFlag(flags.AddrType, cmd.Flag(flags.AddrType))
+	_ = viper.BindPFlag(flags.Coin, cmd.Flag(flags.Coin))
 	_ = viper.BindPFlag(flags.ShowAllKeys, cmd.Flag(flags.ShowAllKeys))
+	_ = viper.BindPFlag(flags.StartIndex, cmd.Flag(flags.StartIndex))
+	_ = viper.BindPFlag(flags.Count, cmd.Flag(flags.Count))
+	_ = viper.BindPFlag(flags.UncompressedWif, cmd.Flag(flags.UncompressedWif))
+	_ = viper.BindPFlag(flags.EncryptWif, cmd.Flag(flags.EncryptWif))
+	_ = viper.BindPFlag(flags.FromKeystore, cmd.Flag(flags.FromKeystore))
+	_ = viper.BindPFlag(flags.KeystoreDir, cmd.Flag(flags.KeystoreDir))
 
 	usePassphrase := viper.GetBool(flags.UsePassphrase)
 	skipMnemonicValidation := viper.GetBool(flags.SkipMnemonicValidation)
@@ -35,122 +36,116 @@ func Gen(cmd *cobra.Command, args []string) error {
 	network := viper.GetString(flags.Network)
 	language := viper.GetString(flags.MnemonicLanguage)
 	scriptType := viper.GetString(flags.AddrType)
+	coin := viper.GetString(flags.Coin)
 	showAllKeys := viper.GetBool(flags.ShowAllKeys)
-
-	prompt, err := prompts.Status()
+	startIndex := viper.GetUint32(flags.StartIndex)
+	count := viper.GetUint32(flags.Count)
+	uncompressedWif := viper.GetBool(flags.UncompressedWif)
+	encryptWif := viper.GetBool(flags.EncryptWif)
+	fromKeystore := viper.GetString(flags.FromKeystore)
+	keystoreDir := viper.GetString(flags.KeystoreDir)
+
+	script, err := getScript(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to get prompt status: %w", err)
+		return fmt.Errorf("failed to get witness script: %w", err)
 	}
 
-	var passphrase string
 	var seed []byte
+	if len(fromKeystore) > 0 {
+		if len(args) > 0 || inputHexSeed || usePassphrase {
+			return fmt.Errorf("mnemonic args, --%s or --%s cannot be used with --%s",
+				flags.InputHexSeed, flags.UsePassphrase, flags.FromKeystore)
+		}
 
-	if inputHexSeed && usePassphrase {
-		return fmt.Errorf("cannot use passphrase when entering seed")
+		seed, err = readKeystoreSeed(cmd, keystoreDir, fromKeystore)
+	} else {
+		seed, err = readSeed(cmd, args, inputHexSeed, usePassphrase, skipMnemonicValidation, language)
+	}
+	if err != nil {
+		return err
 	}
 
-	if inputHexSeed && skipMnemonicValidation {
-		return fmt.Errorf("dont use --skip-mnemonic-validation when entering seed")
+	config := &keys.Config{
+		Seed:           seed,
+		Network:        network,
+		DerivationPath: derivationPath,
+		AddrType:       scriptType,
+		Coin:           coin,
+		Script:         script,
 	}
 
-	if !inputHexSeed {
-		var mnemonic string
-		if len(args) == 0 {
-			if prompt {
-				if err := mnemonics.Prompt(cmd.OutOrStdout()); err != nil {
-					return fmt.Errorf("failed to write to output: %w", err)
-				}
-			}
+	columns := genColumns
+	if showAllKeys {
+		columns = genAllColumns
+	}
 
-			mnemonic, err = mnemonics.Read(cmd.InOrStdin())
-			if err != nil {
-				return fmt.Errorf("failed to read mnemonic from input: %w", err)
-			}
-		} else {
-			mnemonic = mnemonics.NewFromFields(args)
+	// encryption passphrase is read once for all generated keys
+	var passphrase string
+	if encryptWif {
+		passphrase, err = passphrases.New(cmd.OutOrStdout())
+		if err != nil {
+			return fmt.Errorf("failed to get encryption passphrase: %w", err)
 		}
+	}
 
-		if !skipMnemonicValidation {
-			if mnemonic, err = mnemonics.Translate(mnemonic, language, mnemonics.LanguageEnglish); err != nil {
-				return fmt.Errorf("failed to translate mnemonic to English, alternatively try --skip-mnemonic-validation flag: %w", err)
+	rangePath := keys.IsRangeDerivationPath(derivationPath)
+	w, err := newKeyWriter(cmd.OutOrStdout(), persistentFlags, rangePath, columns)
+	if err != nil {
+		return err
+	}
+
+	write := func(key *keys.Key) error {
+		if uncompressedWif {
+			if err := keys.UncompressKey(key); err != nil {
+				return fmt.Errorf("failed to uncompress key: %w", err)
 			}
-		} else {
-			mnemonic = mnemonics.Tidy(mnemonic)
 		}
 
-		if usePassphrase {
-			passphrase, err = passphrases.New(cmd.OutOrStdout())
-			if err != nil {
-				return fmt.Errorf("failed to get passphrase: %w", err)
+		if encryptWif {
+			if err := keys.EncryptKeyWif(key, passphrase); err != nil {
+				return err
 			}
 		}
 
-		seed = seeds.New(mnemonic, passphrase)
-	} else {
-		if len(args) == 0 {
-			if prompt {
-				if err := seeds.Prompt(cmd.OutOrStdout()); err != nil {
-					return fmt.Errorf("failed to prompt for seed: %w", err)
-				}
+		// show less information if not specifically asked,
+		// tabular output selects fields via columns instead
+		if !showAllKeys && !w.tabular() {
+			reduced := &keys.Key{
+				PrvKeyWif:     key.PrvKeyWif,
+				PrvKeyHex:     key.PrvKeyHex,
+				Addr:          key.Addr,
+				KeyOrigin:     key.KeyOrigin,
+				WitnessScript: key.WitnessScript,
+				Descriptor:    key.Descriptor,
 			}
 
-			seed, err = seeds.Read(cmd.InOrStdin())
-			if err != nil {
-				return fmt.Errorf("invalid seed: %w", err)
-			}
-		} else {
-			seed, err = hex.DecodeString(args[0])
-			if err != nil {
-				return fmt.Errorf("failed to decode seed: %w", err)
+			// derivation path distinguishes keys in a batch
+			if rangePath {
+				reduced.DerivationPath = key.DerivationPath
 			}
+
+			key = reduced
 		}
-	}
 
-	key, err := keys.New(
-		&keys.Config{
-			Seed:           seed,
-			Network:        network,
-			DerivationPath: derivationPath,
-			AddrType:       scriptType,
-		},
-	)
-	if err != nil {
-		return fmt.Errorf("failed to generate key: %w", err)
+		return w.write(key)
 	}
 
-	// show less information if not specifically asked
-	if !showAllKeys {
-		key = &keys.Key{
-			Seed:           "",
-			XPrv:           "",
-			XPub:           "",
-			PubKeyHex:      "",
-			PrvKeyWif:      key.PrvKeyWif,
-			Addr:           key.Addr,
-			Network:        "",
-			DerivationPath: "",
-			CoinType:       "",
+	if rangePath {
+		if err := keys.NewRange(config, startIndex, count, write); err != nil {
+			return fmt.Errorf("failed to generate keys: %w", err)
 		}
+
+		return w.close()
 	}
 
-	switch persistentFlags.OutputFormat {
-	case flags.OutputFormatNative, flags.OutputFormatYaml:
-		jb, err := yaml.Marshal(key)
-		if err != nil {
-			return fmt.Errorf("failed to serialize output to json: %w", err)
-		}
-		if _, err := fmt.Fprint(cmd.OutOrStdout(), string(jb)); err != nil {
-			return fmt.Errorf("failed to write to output: %w", err)
-		}
-	case flags.OutputFormatJson:
-		jb, err := json.Marshal(key)
-		if err != nil {
-			return fmt.Errorf("failed to serialize output to json: %w", err)
-		}
-		if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(jb)); err != nil {
-			return fmt.Errorf("failed to write to output: %w", err)
-		}
+	key, err := keys.New(config)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	if err := write(key); err != nil {
+		return err
 	}
 
-	return nil
+	return w.close()
 }
@@ -0,0 +1,181 @@
+package run
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip32/pkg/keystore"
+	"github.com/kubetrail/bip39/pkg/passphrases"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func KeystoreAdd(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.KeystoreDir, cmd.Flag(flags.KeystoreDir))
+	_ = viper.BindPFlag(flags.Cipher, cmd.Flag(flags.Cipher))
+	_ = viper.BindPFlag(flags.InputXPrv, cmd.Flag(flags.InputXPrv))
+	_ = viper.BindPFlag(flags.InputHexSeed, cmd.Flag(flags.InputHexSeed))
+	_ = viper.BindPFlag(flags.UsePassphrase, cmd.Flag(flags.UsePassphrase))
+	_ = viper.BindPFlag(flags.SkipMnemonicValidation, cmd.Flag(flags.SkipMnemonicValidation))
+	_ = viper.BindPFlag(flags.MnemonicLanguage, cmd.Flag(flags.MnemonicLanguage))
+
+	dir := viper.GetString(flags.KeystoreDir)
+	cipherName := viper.GetString(flags.Cipher)
+	inputXPrv := viper.GetBool(flags.InputXPrv)
+	inputHexSeed := viper.GetBool(flags.InputHexSeed)
+	usePassphrase := viper.GetBool(flags.UsePassphrase)
+	skipMnemonicValidation := viper.GetBool(flags.SkipMnemonicValidation)
+	language := viper.GetString(flags.MnemonicLanguage)
+
+	store, err := keystore.New(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open keystore: %w", err)
+	}
+
+	name, args := args[0], args[1:]
+
+	// a single base58 arg of extended key length is an extended key
+	if len(args) == 1 && len(base58.Decode(args[0])) == 82 {
+		inputXPrv = true
+	}
+
+	if inputXPrv && (inputHexSeed || usePassphrase) {
+		return fmt.Errorf("cannot use --%s or --%s with extended private key", flags.InputHexSeed, flags.UsePassphrase)
+	}
+
+	var entryType string
+	var secret []byte
+	if inputXPrv {
+		keyString, err := readKeyString(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		key, err := keys.DecodeExtendedKey(keyString)
+		if err != nil {
+			return fmt.Errorf("failed to decode extended key: %w", err)
+		}
+
+		if len(key.XPrv) == 0 {
+			return fmt.Errorf("extended key is not a private key")
+		}
+
+		entryType, secret = keystore.TypeXPrv, []byte(keyString)
+	} else {
+		seed, err := readSeed(cmd, args, inputHexSeed, usePassphrase, skipMnemonicValidation, language)
+		if err != nil {
+			return err
+		}
+
+		entryType, secret = keystore.TypeSeed, seed
+	}
+
+	passphrase, err := passphrases.New(cmd.OutOrStdout())
+	if err != nil {
+		return fmt.Errorf("failed to get keystore passphrase: %w", err)
+	}
+
+	entry, err := store.Add(name, entryType, secret, passphrase, cipherName)
+	if err != nil {
+		return fmt.Errorf("failed to add keystore entry: %w", err)
+	}
+
+	return writeValue(cmd.OutOrStdout(), persistentFlags, entry)
+}
+
+func KeystoreList(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.KeystoreDir, cmd.Flag(flags.KeystoreDir))
+
+	dir := viper.GetString(flags.KeystoreDir)
+
+	store, err := keystore.New(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open keystore: %w", err)
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list keystore entries: %w", err)
+	}
+
+	return writeValue(cmd.OutOrStdout(), persistentFlags, entries)
+}
+
+func KeystoreRemove(cmd *cobra.Command, args []string) error {
+	_ = viper.BindPFlag(flags.KeystoreDir, cmd.Flag(flags.KeystoreDir))
+
+	dir := viper.GetString(flags.KeystoreDir)
+
+	store, err := keystore.New(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open keystore: %w", err)
+	}
+
+	if err := store.Remove(args[0]); err != nil {
+		return fmt.Errorf("failed to remove keystore entry: %w", err)
+	}
+
+	return nil
+}
+
+func KeystoreExport(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.KeystoreDir, cmd.Flag(flags.KeystoreDir))
+
+	dir := viper.GetString(flags.KeystoreDir)
+
+	secret, err := readKeystoreSecret(cmd, dir, args[0], "")
+	if err != nil {
+		return err
+	}
+
+	return writeValue(cmd.OutOrStdout(), persistentFlags, secret)
+}
+
+// readKeystoreSecret prompts for keystore passphrase and decrypts the
+// entry, which is required to be of the entry type when not empty
+func readKeystoreSecret(cmd *cobra.Command, dir, name, entryType string) (*keystore.Secret, error) {
+	store, err := keystore.New(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keystore: %w", err)
+	}
+
+	passphrase, err := passphrases.Prompt(cmd.OutOrStdout())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keystore passphrase: %w", err)
+	}
+
+	secret, err := store.Export(name, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore entry: %w", err)
+	}
+
+	if len(entryType) > 0 && secret.Type != entryType {
+		return nil, fmt.Errorf("keystore entry %s holds %s, expected %s", name, secret.Type, entryType)
+	}
+
+	return secret, nil
+}
+
+// readKeystoreSeed returns decoded seed of keystore entry
+func readKeystoreSeed(cmd *cobra.Command, dir, name string) ([]byte, error) {
+	secret, err := readKeystoreSecret(cmd, dir, name, keystore.TypeSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := hex.DecodeString(secret.Seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode seed: %w", err)
+	}
+
+	return seed, nil
+}
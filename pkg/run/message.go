@@ -0,0 +1,138 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func SignMessage(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.Message, cmd.Flag(flags.Message))
+	_ = viper.BindPFlag(flags.AddrType, cmd.Flag(flags.AddrType))
+	_ = viper.BindPFlag(flags.SignatureFormat, cmd.Flag(flags.SignatureFormat))
+
+	message := viper.GetString(flags.Message)
+	addrType := viper.GetString(flags.AddrType)
+	format := strings.ToLower(viper.GetString(flags.SignatureFormat))
+
+	key, err := messageKey(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	var signed *keys.SignedMessage
+	if format == keys.MessageFormatLegacy {
+		signed, err = keys.SignMessage(key.PrvKeyWif, addrType, message)
+	} else {
+		signed, err = keys.SignMessageBip322(key, message, format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	return writeValue(cmd.OutOrStdout(), persistentFlags, signed)
+}
+
+func VerifyMessage(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.Message, cmd.Flag(flags.Message))
+	_ = viper.BindPFlag(flags.Signature, cmd.Flag(flags.Signature))
+	_ = viper.BindPFlag(flags.Addr, cmd.Flag(flags.Addr))
+	_ = viper.BindPFlag(flags.SignatureFormat, cmd.Flag(flags.SignatureFormat))
+
+	message := viper.GetString(flags.Message)
+	signature := viper.GetString(flags.Signature)
+	addr := viper.GetString(flags.Addr)
+	format := strings.ToLower(viper.GetString(flags.SignatureFormat))
+
+	// address is derived from the key material
+	// when not provided explicitly
+	if len(addr) == 0 {
+		key, err := messageKey(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		addr = key.Addr
+	} else if len(args) > 0 {
+		return fmt.Errorf("provide either an address using --%s flag or key material as args, not both", flags.Addr)
+	}
+
+	var verified *keys.SignedMessage
+	var err error
+	if format == keys.MessageFormatLegacy {
+		verified, err = keys.VerifyMessage(addr, signature, message)
+	} else {
+		verified, err = keys.VerifyMessageBip322(addr, signature, message, format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to verify message: %w", err)
+	}
+
+	return writeValue(cmd.OutOrStdout(), persistentFlags, verified)
+}
+
+// messageKey returns the key for message signing from a wif private
+// key or from a mnemonic or seed along with the derivation path. Key
+// address corresponds to the address type in either case
+func messageKey(cmd *cobra.Command, args []string) (*keys.Key, error) {
+	_ = viper.BindPFlag(flags.AddrType, cmd.Flag(flags.AddrType))
+	addrType := viper.GetString(flags.AddrType)
+
+	if len(args) == 1 {
+		switch len(base58.Decode(args[0])) {
+		case 37, 38: // uncompressed and compressed wif private keys
+			key, err := keys.DecodePrivateWifKey(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode private wif key: %w", err)
+			}
+
+			if key.Addr, err = keys.SingleKeyAddr(key.PubKeyHex, addrType, key.Network); err != nil {
+				return nil, fmt.Errorf("failed to generate address: %w", err)
+			}
+
+			return key, nil
+		}
+	}
+
+	_ = viper.BindPFlag(flags.UsePassphrase, cmd.Flag(flags.UsePassphrase))
+	_ = viper.BindPFlag(flags.SkipMnemonicValidation, cmd.Flag(flags.SkipMnemonicValidation))
+	_ = viper.BindPFlag(flags.DerivationPath, cmd.Flag(flags.DerivationPath))
+	_ = viper.BindPFlag(flags.InputHexSeed, cmd.Flag(flags.InputHexSeed))
+	_ = viper.BindPFlag(flags.Network, cmd.Flag(flags.Network))
+	_ = viper.BindPFlag(flags.MnemonicLanguage, cmd.Flag(flags.MnemonicLanguage))
+
+	usePassphrase := viper.GetBool(flags.UsePassphrase)
+	skipMnemonicValidation := viper.GetBool(flags.SkipMnemonicValidation)
+	derivationPath := viper.GetString(flags.DerivationPath)
+	inputHexSeed := viper.GetBool(flags.InputHexSeed)
+	network := viper.GetString(flags.Network)
+	language := viper.GetString(flags.MnemonicLanguage)
+
+	seed, err := readSeed(cmd, args, inputHexSeed, usePassphrase, skipMnemonicValidation, language)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keys.New(
+		&keys.Config{
+			Seed:           seed,
+			Network:        network,
+			DerivationPath: derivationPath,
+			AddrType:       addrType,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,552 @@
+package run
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"gopkg.in/yaml.v3"
+)
+
+// keyWriter writes keys to the output in the requested format. When list
+// is set, keys are streamed as elements of a list as they are written, so
+// that large batches do not need to be held in memory. Table output renders
+// the columns as aligned rows and is flushed when the writer is closed
+type keyWriter struct {
+	w         io.Writer
+	format    string
+	list      bool
+	columns   []string
+	selected  bool
+	noHeaders bool
+	table     *tabwriter.Writer
+	csv       *csv.Writer
+	count     int
+}
+
+// newKeyWriter returns a key writer for output format and columns selected
+// via persistent flags. Default columns are used when none are selected
+func newKeyWriter(w io.Writer, persistentFlags persistentFlagValues, list bool, defaultColumns []string) (*keyWriter, error) {
+	format := persistentFlags.OutputFormat
+	columns, selected := persistentFlags.Columns, len(persistentFlags.Columns) > 0
+
+	switch format {
+	case flags.OutputFormatNative, flags.OutputFormatYaml, flags.OutputFormatJson:
+		if selected {
+			return nil, fmt.Errorf("columns can only be selected for %v output formats",
+				[]string{flags.OutputFormatTable, flags.OutputFormatCsv, flags.OutputFormatJsonl},
+			)
+		}
+	case flags.OutputFormatTable, flags.OutputFormatCsv, flags.OutputFormatJsonl:
+		if !selected {
+			columns = defaultColumns
+		}
+
+		for _, column := range columns {
+			if !containsString(keyColumns, column) {
+				return nil, fmt.Errorf("invalid column %s. allowed columns are %v", column, ColumnNames())
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid or unsupported output format: %s. allowed formats are %v", format,
+			[]string{
+				flags.OutputFormatNative,
+				flags.OutputFormatJson,
+				flags.OutputFormatYaml,
+				flags.OutputFormatTable,
+				flags.OutputFormatCsv,
+				flags.OutputFormatJsonl,
+			},
+		)
+	}
+
+	return &keyWriter{
+		w:         w,
+		format:    format,
+		list:      list,
+		columns:   columns,
+		selected:  selected,
+		noHeaders: persistentFlags.NoHeaders,
+	}, nil
+}
+
+// tabular returns true if keys are rendered as columns. Jsonl output
+// renders full keys unless columns are explicitly selected
+func (k *keyWriter) tabular() bool {
+	switch k.format {
+	case flags.OutputFormatTable, flags.OutputFormatCsv:
+		return true
+	case flags.OutputFormatJsonl:
+		return k.selected
+	default:
+		return false
+	}
+}
+
+// row returns json encoded fields of the key
+func (k *keyWriter) row(key *keys.Key) (map[string]json.RawMessage, error) {
+	jb, err := json.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize output to json: %w", err)
+	}
+
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(jb, &row); err != nil {
+		return nil, fmt.Errorf("failed to serialize output to json: %w", err)
+	}
+
+	return row, nil
+}
+
+// values returns column values for the key
+func (k *keyWriter) values(key *keys.Key) ([]string, error) {
+	row, err := k.row(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cellValues(row, k.columns), nil
+}
+
+// write writes a single key record to the output
+func (k *keyWriter) write(key *keys.Key) error {
+	defer func() { k.count++ }()
+
+	switch k.format {
+	case flags.OutputFormatNative, flags.OutputFormatYaml:
+		var v interface{} = key
+		if k.list {
+			// marshaling a single element list allows
+			// concatenated output to form a valid yaml list
+			v = []*keys.Key{key}
+		}
+		jb, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to serialize output to yaml: %w", err)
+		}
+		if _, err := fmt.Fprint(k.w, string(jb)); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+	case flags.OutputFormatJson:
+		jb, err := json.Marshal(key)
+		if err != nil {
+			return fmt.Errorf("failed to serialize output to json: %w", err)
+		}
+
+		if !k.list {
+			if _, err := fmt.Fprintln(k.w, string(jb)); err != nil {
+				return fmt.Errorf("failed to write to output: %w", err)
+			}
+			return nil
+		}
+
+		prefix := ","
+		if k.count == 0 {
+			prefix = "["
+		}
+		if _, err := fmt.Fprint(k.w, prefix, string(jb)); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+	case flags.OutputFormatJsonl:
+		jb, err := k.jsonLine(key)
+		if err != nil {
+			return fmt.Errorf("failed to serialize output to json: %w", err)
+		}
+		if _, err := fmt.Fprintln(k.w, string(jb)); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+	case flags.OutputFormatTable:
+		if k.table == nil {
+			k.table = tabwriter.NewWriter(k.w, 0, 0, 2, ' ', 0)
+			if !k.noHeaders {
+				headers := make([]string, len(k.columns))
+				for i, column := range k.columns {
+					headers[i] = strings.ToUpper(column)
+				}
+				if _, err := fmt.Fprintln(k.table, strings.Join(headers, "\t")); err != nil {
+					return fmt.Errorf("failed to write to output: %w", err)
+				}
+			}
+		}
+
+		values, err := k.values(key)
+		if err != nil {
+			return err
+		}
+		for i := range values {
+			if len(values[i]) == 0 {
+				values[i] = "-"
+			}
+		}
+		if _, err := fmt.Fprintln(k.table, strings.Join(values, "\t")); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+	case flags.OutputFormatCsv:
+		if k.csv == nil {
+			k.csv = csv.NewWriter(k.w)
+			if !k.noHeaders {
+				if err := k.csv.Write(k.columns); err != nil {
+					return fmt.Errorf("failed to write to output: %w", err)
+				}
+			}
+		}
+
+		values, err := k.values(key)
+		if err != nil {
+			return err
+		}
+
+		if err := k.csv.Write(values); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+
+		// flush each record so that rows are streamed
+		k.csv.Flush()
+		if err := k.csv.Error(); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// jsonLine serializes key as a single line json object. When columns
+// are selected, only those fields are included in the column order
+func (k *keyWriter) jsonLine(key *keys.Key) ([]byte, error) {
+	if !k.selected {
+		return json.Marshal(key)
+	}
+
+	row, err := k.row(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonLineColumns(row, k.columns)
+}
+
+// close terminates list output and flushes table rows
+func (k *keyWriter) close() error {
+	switch k.format {
+	case flags.OutputFormatNative, flags.OutputFormatYaml:
+		if k.list && k.count == 0 {
+			if _, err := fmt.Fprintln(k.w, "[]"); err != nil {
+				return fmt.Errorf("failed to write to output: %w", err)
+			}
+		}
+	case flags.OutputFormatJson:
+		if !k.list {
+			return nil
+		}
+
+		suffix := "]"
+		if k.count == 0 {
+			suffix = "[]"
+		}
+		if _, err := fmt.Fprintln(k.w, suffix); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+	case flags.OutputFormatTable:
+		if k.table == nil {
+			return nil
+		}
+
+		if err := k.table.Flush(); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeValue writes a value that is not a key, such as a psbt summary or
+// a signed message. Table, csv and jsonl output render top level fields of
+// the value as columns named after their json fields, with one row per
+// element when the value is a list. Columns default to the fields set on
+// at least one row
+func writeValue(w io.Writer, persistentFlags persistentFlagValues, v interface{}) error {
+	return writeValueColumns(w, persistentFlags, v, nil)
+}
+
+// writeValueColumns writes value similar to writeValue, however, table and
+// csv output use default columns when no columns are selected, keeping rows
+// uniform across values with different fields set
+func writeValueColumns(w io.Writer, persistentFlags persistentFlagValues, v interface{}, defaultColumns []string) error {
+	format := persistentFlags.OutputFormat
+	columns, selected := persistentFlags.Columns, len(persistentFlags.Columns) > 0
+
+	switch format {
+	case flags.OutputFormatNative, flags.OutputFormatYaml, flags.OutputFormatJson:
+		if selected {
+			return fmt.Errorf("columns can only be selected for %v output formats",
+				[]string{flags.OutputFormatTable, flags.OutputFormatCsv, flags.OutputFormatJsonl},
+			)
+		}
+	case flags.OutputFormatTable, flags.OutputFormatCsv, flags.OutputFormatJsonl:
+	default:
+		return fmt.Errorf("invalid or unsupported output format: %s. allowed formats are %v", format,
+			[]string{
+				flags.OutputFormatNative,
+				flags.OutputFormatJson,
+				flags.OutputFormatYaml,
+				flags.OutputFormatTable,
+				flags.OutputFormatCsv,
+				flags.OutputFormatJsonl,
+			},
+		)
+	}
+
+	switch format {
+	case flags.OutputFormatNative, flags.OutputFormatYaml:
+		jb, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to serialize output to yaml: %w", err)
+		}
+		if _, err := fmt.Fprint(w, string(jb)); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+		return nil
+	case flags.OutputFormatJson:
+		jb, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to serialize output to json: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, string(jb)); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+		return nil
+	}
+
+	fields, rows, err := valueRows(v)
+	if err != nil {
+		return err
+	}
+
+	// jsonl output renders all fields unless columns are selected
+	if !selected && len(defaultColumns) > 0 && format != flags.OutputFormatJsonl {
+		columns, selected = defaultColumns, true
+	}
+
+	if selected {
+		for _, column := range columns {
+			if !containsString(fields, column) {
+				return fmt.Errorf("invalid column %s. allowed columns are %v", column, fields)
+			}
+		}
+	} else {
+		columns = nil
+		for _, field := range fields {
+			for _, row := range rows {
+				if _, ok := row[field]; ok {
+					columns = append(columns, field)
+					break
+				}
+			}
+		}
+	}
+
+	switch format {
+	case flags.OutputFormatJsonl:
+		for _, row := range rows {
+			jb, err := jsonLineColumns(row, columns)
+			if err != nil {
+				return fmt.Errorf("failed to serialize output to json: %w", err)
+			}
+
+			if _, err := fmt.Fprintln(w, string(jb)); err != nil {
+				return fmt.Errorf("failed to write to output: %w", err)
+			}
+		}
+	case flags.OutputFormatTable:
+		if len(rows) == 0 {
+			return nil
+		}
+
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if !persistentFlags.NoHeaders {
+			headers := make([]string, len(columns))
+			for i, column := range columns {
+				headers[i] = strings.ToUpper(column)
+			}
+			if _, err := fmt.Fprintln(table, strings.Join(headers, "\t")); err != nil {
+				return fmt.Errorf("failed to write to output: %w", err)
+			}
+		}
+
+		for _, row := range rows {
+			values := cellValues(row, columns)
+			for i := range values {
+				if len(values[i]) == 0 {
+					values[i] = "-"
+				}
+			}
+			if _, err := fmt.Fprintln(table, strings.Join(values, "\t")); err != nil {
+				return fmt.Errorf("failed to write to output: %w", err)
+			}
+		}
+
+		if err := table.Flush(); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+	case flags.OutputFormatCsv:
+		cw := csv.NewWriter(w)
+		if !persistentFlags.NoHeaders {
+			if err := cw.Write(columns); err != nil {
+				return fmt.Errorf("failed to write to output: %w", err)
+			}
+		}
+
+		for _, row := range rows {
+			if err := cw.Write(cellValues(row, columns)); err != nil {
+				return fmt.Errorf("failed to write to output: %w", err)
+			}
+		}
+
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// valueRows returns json field names of the value type in declaration
+// order along with json encoded fields of the value, or of each element
+// when the value is a list
+func valueRows(v interface{}) ([]string, []map[string]json.RawMessage, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	} else {
+		v = []interface{}{v}
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("output cannot be rendered as columns")
+	}
+
+	jb, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize output to json: %w", err)
+	}
+
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(jb, &rows); err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize output to json: %w", err)
+	}
+
+	return jsonFieldNames(t), rows, nil
+}
+
+// jsonFieldNames returns json names of exported struct fields
+// including fields of embedded structs
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && len(name) == 0 {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				names = append(names, jsonFieldNames(ft)...)
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// jsonLineColumns serializes json fields of the row as a single line
+// json object with fields in the column order and null for fields
+// that are not set
+func jsonLineColumns(row map[string]json.RawMessage, columns []string) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		name, err := json.Marshal(column)
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := row[column]
+		if !ok {
+			value = json.RawMessage("null")
+		}
+
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// cellValues renders json fields of the row as column values,
+// unquoting strings and leaving other values as json
+func cellValues(row map[string]json.RawMessage, columns []string) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		raw, ok := row[column]
+		if !ok {
+			continue
+		}
+
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			values[i] = s
+			continue
+		}
+
+		values[i] = string(raw)
+	}
+
+	return values
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
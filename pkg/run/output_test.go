@@ -0,0 +1,289 @@
+package run
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+)
+
+func TestKeyWriter_Table(t *testing.T) {
+	var b bytes.Buffer
+	w, err := newKeyWriter(&b, persistentFlagValues{OutputFormat: flags.OutputFormatTable}, true, []string{columnDerivationPath, columnAddr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []*keys.Key{
+		{DerivationPath: "m/0/9", Addr: "addr9"},
+		{DerivationPath: "m/0/10", Addr: "addr10"},
+		{DerivationPath: "m/0/11"},
+	} {
+		if err := w.write(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "DERIVATIONPATH  ADDR\n" +
+		"m/0/9           addr9\n" +
+		"m/0/10          addr10\n" +
+		"m/0/11          -\n"
+	if b.String() != expected {
+		t.Fatal("expected", expected, ", got", b.String())
+	}
+}
+
+func TestKeyWriter_JsonList(t *testing.T) {
+	for _, n := range []int{0, 1, 3} {
+		var b bytes.Buffer
+		w, err := newKeyWriter(&b, persistentFlagValues{OutputFormat: flags.OutputFormatJson}, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < n; i++ {
+			if err := w.write(&keys.Key{Addr: "addr"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := w.close(); err != nil {
+			t.Fatal(err)
+		}
+
+		var output []*keys.Key
+		if err := json.Unmarshal(b.Bytes(), &output); err != nil {
+			t.Fatal(err, b.String())
+		}
+
+		if len(output) != n {
+			t.Fatal("expected", n, "keys, got", len(output))
+		}
+	}
+}
+
+func TestKeyWriter_InvalidFormat(t *testing.T) {
+	if _, err := newKeyWriter(&bytes.Buffer{}, persistentFlagValues{OutputFormat: "xml"}, false, nil); err == nil {
+		t.Fatal("expected error for invalid output format")
+	}
+
+	if _, err := newKeyWriter(&bytes.Buffer{}, persistentFlagValues{OutputFormat: flags.OutputFormatCsv, Columns: []string{"xyz"}}, false, nil); err == nil {
+		t.Fatal("expected error for invalid column")
+	}
+
+	if _, err := newKeyWriter(&bytes.Buffer{}, persistentFlagValues{OutputFormat: flags.OutputFormatJson, Columns: []string{columnAddr}}, false, nil); err == nil {
+		t.Fatal("expected error for columns with json output")
+	}
+}
+
+func TestKeyWriter_Csv(t *testing.T) {
+	key := &keys.Key{
+		DerivationPath: "m/84h/1h/0h/0/0",
+		Addr:           "tb1qmtaxa29wfzgx9mktqvezdvvl009s696l8xhamt",
+		PubKeyHex:      "03b2a596505a6af072fbe656554e7260cfc3f157b590bfc539e6032c672377fd1c",
+		PrvKeyWif:      "cTnQBnDdjAk4zZL4YbeLs2CwZom88M8KSXyScrZgj6voVsrk4ZUu",
+	}
+
+	tests := []struct {
+		persistentFlags persistentFlagValues
+		expected        string
+	}{
+		{
+			persistentFlags: persistentFlagValues{
+				OutputFormat: flags.OutputFormatCsv,
+				NoHeaders:    true,
+			},
+			expected: "m/84h/1h/0h/0/0,tb1qmtaxa29wfzgx9mktqvezdvvl009s696l8xhamt,03b2a596505a6af072fbe656554e7260cfc3f157b590bfc539e6032c672377fd1c,cTnQBnDdjAk4zZL4YbeLs2CwZom88M8KSXyScrZgj6voVsrk4ZUu\n",
+		},
+		{
+			persistentFlags: persistentFlagValues{
+				OutputFormat: flags.OutputFormatCsv,
+				Columns:      []string{columnAddr, columnDerivationPath},
+			},
+			expected: "addr,derivationPath\ntb1qmtaxa29wfzgx9mktqvezdvvl009s696l8xhamt,m/84h/1h/0h/0/0\n",
+		},
+		{
+			persistentFlags: persistentFlagValues{
+				OutputFormat: flags.OutputFormatJsonl,
+				Columns:      []string{columnDerivationPath, columnAddr},
+			},
+			expected: `{"derivationPath":"m/84h/1h/0h/0/0","addr":"tb1qmtaxa29wfzgx9mktqvezdvvl009s696l8xhamt"}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		var b bytes.Buffer
+		w, err := newKeyWriter(&b, test.persistentFlags, true, []string{columnDerivationPath, columnAddr, columnPubKeyHex, columnPrvKeyWif})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := w.write(key); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := w.close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if b.String() != test.expected {
+			t.Fatal("expected", test.expected, ", got", b.String())
+		}
+	}
+}
+
+func TestWriteValue_Tabular(t *testing.T) {
+	type embedded struct {
+		Note string `json:"note,omitempty"`
+	}
+
+	type value struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+		Flag  *bool  `json:"flag,omitempty"`
+		embedded
+	}
+
+	flag := true
+	values := []*value{
+		{Name: "a", Count: 1, Flag: &flag},
+		{Name: "b", Count: 2, embedded: embedded{Note: "x"}},
+	}
+
+	tests := []struct {
+		persistentFlags persistentFlagValues
+		v               interface{}
+		expected        string
+	}{
+		{
+			persistentFlags: persistentFlagValues{OutputFormat: flags.OutputFormatTable},
+			v:               values,
+			expected: "NAME  COUNT  FLAG  NOTE\n" +
+				"a     1      true  -\n" +
+				"b     2      -     x\n",
+		},
+		{
+			persistentFlags: persistentFlagValues{OutputFormat: flags.OutputFormatCsv, Columns: []string{"note", "name"}},
+			v:               values,
+			expected:        "note,name\n,a\nx,b\n",
+		},
+		{
+			persistentFlags: persistentFlagValues{OutputFormat: flags.OutputFormatCsv, NoHeaders: true},
+			v:               values[0],
+			expected:        "a,1,true\n",
+		},
+		{
+			persistentFlags: persistentFlagValues{OutputFormat: flags.OutputFormatJsonl, Columns: []string{"count", "flag"}},
+			v:               values,
+			expected:        `{"count":1,"flag":true}` + "\n" + `{"count":2,"flag":null}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		var b bytes.Buffer
+		if err := writeValue(&b, test.persistentFlags, test.v); err != nil {
+			t.Fatal(err)
+		}
+
+		if b.String() != test.expected {
+			t.Fatal("expected", test.expected, ", got", b.String())
+		}
+	}
+
+	for _, persistentFlags := range []persistentFlagValues{
+		{OutputFormat: flags.OutputFormatCsv, Columns: []string{"xyz"}},
+		{OutputFormat: flags.OutputFormatYaml, Columns: []string{"name"}},
+		{OutputFormat: "xml"},
+	} {
+		if err := writeValue(&bytes.Buffer{}, persistentFlags, values); err == nil {
+			t.Fatal("expected error for", persistentFlags)
+		}
+	}
+
+	if err := writeValue(&bytes.Buffer{}, persistentFlagValues{OutputFormat: flags.OutputFormatTable}, "abc"); err == nil {
+		t.Fatal("expected error for value without fields")
+	}
+}
+
+func TestWriteValueColumns_Address(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected string
+	}{
+		{
+			addr:     "1MJ9PojuE1rA1E8wtrdQXjxaqZdsgddhoh",
+			expected: "1MJ9PojuE1rA1E8wtrdQXjxaqZdsgddhoh,mainnet,p2pkh,76a914dea097e3d4e0ea387092e30fa2c28e62711a3aef88ac\n",
+		},
+		{
+			addr:     "bc1qsah54m5u94ktfymcv4jf656rqnu9dxnuhcjvx8",
+			expected: "bc1qsah54m5u94ktfymcv4jf656rqnu9dxnuhcjvx8,mainnet,p2wpkh,0014876f4aee9c2d6cb4937865649d534304f8569a7c\n",
+		},
+	}
+
+	for _, test := range tests {
+		address, err := keys.DecodeAddress(test.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b bytes.Buffer
+		if err := writeValueColumns(
+			&b,
+			persistentFlagValues{OutputFormat: flags.OutputFormatCsv, NoHeaders: true},
+			address,
+			addressColumns,
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		if b.String() != test.expected {
+			t.Fatal("expected", test.expected, ", got", b.String())
+		}
+	}
+}
+
+func TestKeyColumns_JsonFieldNames(t *testing.T) {
+	for _, columns := range [][]string{genColumns, genAllColumns, deriveColumns, decodeColumns, descriptorColumns} {
+		for _, column := range columns {
+			if !containsString(keyColumns, column) {
+				t.Fatal("default column is not a json field of key:", column)
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	w, err := newKeyWriter(
+		&b,
+		persistentFlagValues{
+			OutputFormat: flags.OutputFormatJsonl,
+			Columns:      []string{"masterFingerprint", "depth", "outputKey"},
+		},
+		true,
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	depth := uint8(5)
+	key := &keys.Key{MasterFingerprint: "73c5da0a"}
+	key.Depth = &depth
+	if err := w.write(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"masterFingerprint":"73c5da0a","depth":5,"outputKey":null}` + "\n"
+	if b.String() != expected {
+		t.Fatal("expected", expected, ", got", b.String())
+	}
+}
@@ -0,0 +1,237 @@
+package run
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keystore"
+	"github.com/kubetrail/bip32/pkg/psbt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func PsbtSign(cmd *cobra.Command, args []string) error {
+	_ = viper.BindPFlag(flags.UsePassphrase, cmd.Flag(flags.UsePassphrase))
+	_ = viper.BindPFlag(flags.InputHexSeed, cmd.Flag(flags.InputHexSeed))
+	_ = viper.BindPFlag(flags.MnemonicLanguage, cmd.Flag(flags.MnemonicLanguage))
+	_ = viper.BindPFlag(flags.SkipMnemonicValidation, cmd.Flag(flags.SkipMnemonicValidation))
+	_ = viper.BindPFlag(flags.FromKeystore, cmd.Flag(flags.FromKeystore))
+	_ = viper.BindPFlag(flags.KeystoreDir, cmd.Flag(flags.KeystoreDir))
+	_ = viper.BindPFlag(flags.PsbtFile, cmd.Flag(flags.PsbtFile))
+	_ = viper.BindPFlag(flags.AllowSighash, cmd.Flag(flags.AllowSighash))
+
+	usePassphrase := viper.GetBool(flags.UsePassphrase)
+	inputHexSeed := viper.GetBool(flags.InputHexSeed)
+	language := viper.GetString(flags.MnemonicLanguage)
+	skipMnemonicValidation := viper.GetBool(flags.SkipMnemonicValidation)
+	fromKeystore := viper.GetString(flags.FromKeystore)
+	keystoreDir := viper.GetString(flags.KeystoreDir)
+	psbtFile := viper.GetString(flags.PsbtFile)
+	allowSighash := viper.GetStringSlice(flags.AllowSighash)
+
+	// psbt is read from STDIN unless read from a file, in which case
+	// key material and passphrases cannot be read from STDIN
+	if len(psbtFile) == 0 {
+		if len(args) == 0 && len(fromKeystore) == 0 {
+			return fmt.Errorf("key is required as args when psbt is read from input, alternatively use --%s", flags.PsbtFile)
+		}
+
+		if usePassphrase || len(fromKeystore) > 0 {
+			return fmt.Errorf("--%s is required when using --%s or --%s", flags.PsbtFile, flags.UsePassphrase, flags.FromKeystore)
+		}
+	}
+
+	var signer *psbt.Signer
+	var err error
+	switch {
+	case len(fromKeystore) > 0:
+		if len(args) > 0 || inputHexSeed || usePassphrase {
+			return fmt.Errorf("key args, --%s or --%s cannot be used with --%s",
+				flags.InputHexSeed, flags.UsePassphrase, flags.FromKeystore)
+		}
+
+		secret, err := readKeystoreSecret(cmd, keystoreDir, fromKeystore, "")
+		if err != nil {
+			return err
+		}
+
+		if secret.Type == keystore.TypeXPrv {
+			signer, err = psbt.NewSigner(secret.XPrv)
+		} else {
+			var seed []byte
+			if seed, err = hex.DecodeString(secret.Seed); err != nil {
+				return fmt.Errorf("failed to decode seed: %w", err)
+			}
+			signer, err = psbt.NewSeedSigner(seed)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create signer: %w", err)
+		}
+	case len(args) == 1 && !inputHexSeed && len(base58.Decode(args[0])) == 82:
+		if usePassphrase {
+			return fmt.Errorf("cannot use passphrase with extended private key")
+		}
+
+		signer, err = psbt.NewSigner(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to create signer: %w", err)
+		}
+	default:
+		seed, err := readSeed(cmd, args, inputHexSeed, usePassphrase, skipMnemonicValidation, language)
+		if err != nil {
+			return err
+		}
+
+		signer, err = psbt.NewSeedSigner(seed)
+		if err != nil {
+			return fmt.Errorf("failed to create signer: %w", err)
+		}
+	}
+
+	for _, name := range allowSighash {
+		if err := signer.AllowSigHash(name); err != nil {
+			return err
+		}
+	}
+
+	var b []byte
+	if len(psbtFile) > 0 {
+		b, err = os.ReadFile(psbtFile)
+	} else {
+		b, err = io.ReadAll(cmd.InOrStdin())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read psbt from input: %w", err)
+	}
+
+	packet, isBase64, err := psbt.Decode(b)
+	if err != nil {
+		return fmt.Errorf("failed to decode psbt: %w", err)
+	}
+
+	n, err := packet.Sign(signer)
+	if err != nil {
+		return fmt.Errorf("failed to sign psbt: %w", err)
+	}
+
+	if n == 0 {
+		return fmt.Errorf("no psbt inputs matched key with fingerprint %s", signer.Fingerprint())
+	}
+
+	if !isBase64 {
+		var out bytes.Buffer
+		if err := packet.Serialize(&out); err != nil {
+			return fmt.Errorf("failed to serialize psbt: %w", err)
+		}
+
+		if _, err := cmd.OutOrStdout().Write(out.Bytes()); err != nil {
+			return fmt.Errorf("failed to write to output: %w", err)
+		}
+
+		return nil
+	}
+
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return fmt.Errorf("failed to serialize psbt: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(cmd.OutOrStdout(), b64); err != nil {
+		return fmt.Errorf("failed to write to output: %w", err)
+	}
+
+	return nil
+}
+
+func PsbtInspect(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.Network, cmd.Flag(flags.Network))
+	network := viper.GetString(flags.Network)
+
+	var owner *psbt.Owner
+	if len(args) > 0 {
+		var err error
+		if owner, err = psbt.NewOwner(args[0]); err != nil {
+			return fmt.Errorf("failed to create owner: %w", err)
+		}
+	}
+
+	b, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return fmt.Errorf("failed to read psbt from input: %w", err)
+	}
+
+	packet, _, err := psbt.Decode(b)
+	if err != nil {
+		return fmt.Errorf("failed to decode psbt: %w", err)
+	}
+
+	summary, err := packet.Inspect(owner, network)
+	if err != nil {
+		return fmt.Errorf("failed to inspect psbt: %w", err)
+	}
+
+	// tabular output lists inputs and outputs as rows
+	switch persistentFlags.OutputFormat {
+	case flags.OutputFormatTable, flags.OutputFormatCsv, flags.OutputFormatJsonl:
+		return writeValue(cmd.OutOrStdout(), persistentFlags, summaryRows(summary))
+	}
+
+	return writeValue(cmd.OutOrStdout(), persistentFlags, summary)
+}
+
+// summaryRow is a psbt input, output or fee rendered as a row
+// of table, csv or jsonl output
+type summaryRow struct {
+	Kind       string `json:"kind"`
+	Index      *int   `json:"index,omitempty"`
+	Amount     string `json:"amount,omitempty"`
+	ScriptType string `json:"scriptType,omitempty"`
+	Addr       string `json:"addr,omitempty"`
+	Owned      *bool  `json:"owned,omitempty"`
+	Change     *bool  `json:"change,omitempty"`
+	Path       string `json:"path,omitempty"`
+}
+
+// summaryRows flattens psbt inputs, outputs and fee into rows
+func summaryRows(summary *psbt.Summary) []*summaryRow {
+	rows := make([]*summaryRow, 0, len(summary.Inputs)+len(summary.Outputs)+1)
+	for _, input := range summary.Inputs {
+		index := input.Index
+		rows = append(rows, &summaryRow{
+			Kind:       "input",
+			Index:      &index,
+			Amount:     input.Amount,
+			ScriptType: input.ScriptType,
+			Addr:       input.Addr,
+			Owned:      input.Owned,
+			Path:       input.DerivationPath,
+		})
+	}
+
+	for _, output := range summary.Outputs {
+		index := output.Index
+		rows = append(rows, &summaryRow{
+			Kind:       "output",
+			Index:      &index,
+			Amount:     output.Amount,
+			ScriptType: output.ScriptType,
+			Addr:       output.Addr,
+			Owned:      output.Owned,
+			Change:     output.Change,
+			Path:       output.DerivationPath,
+		})
+	}
+
+	if len(summary.Fee) > 0 {
+		rows = append(rows, &summaryRow{Kind: "fee", Amount: summary.Fee})
+	}
+
+	return rows
+}
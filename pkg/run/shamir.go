@@ -0,0 +1,228 @@
+package run
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip32/pkg/slip39"
+	"github.com/kubetrail/bip39/pkg/passphrases"
+	"github.com/kubetrail/bip39/pkg/prompts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// shamirShare is a SLIP-39 mnemonic share along with its
+// group and member numbers starting at 1
+type shamirShare struct {
+	Group    int    `json:"group" yaml:"group"`
+	Member   int    `json:"member" yaml:"member"`
+	Mnemonic string `json:"mnemonic" yaml:"mnemonic"`
+}
+
+func ShamirSplit(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.UsePassphrase, cmd.Flag(flags.UsePassphrase))
+	_ = viper.BindPFlag(flags.SkipMnemonicValidation, cmd.Flag(flags.SkipMnemonicValidation))
+	_ = viper.BindPFlag(flags.InputHexSeed, cmd.Flag(flags.InputHexSeed))
+	_ = viper.BindPFlag(flags.MnemonicLanguage, cmd.Flag(flags.MnemonicLanguage))
+	_ = viper.BindPFlag(flags.FromKeystore, cmd.Flag(flags.FromKeystore))
+	_ = viper.BindPFlag(flags.KeystoreDir, cmd.Flag(flags.KeystoreDir))
+	_ = viper.BindPFlag(flags.GroupThreshold, cmd.Flag(flags.GroupThreshold))
+	_ = viper.BindPFlag(flags.Groups, cmd.Flag(flags.Groups))
+	_ = viper.BindPFlag(flags.IterationExponent, cmd.Flag(flags.IterationExponent))
+	_ = viper.BindPFlag(flags.Extendable, cmd.Flag(flags.Extendable))
+	_ = viper.BindPFlag(flags.UseShamirPassphrase, cmd.Flag(flags.UseShamirPassphrase))
+
+	usePassphrase := viper.GetBool(flags.UsePassphrase)
+	skipMnemonicValidation := viper.GetBool(flags.SkipMnemonicValidation)
+	inputHexSeed := viper.GetBool(flags.InputHexSeed)
+	language := viper.GetString(flags.MnemonicLanguage)
+	fromKeystore := viper.GetString(flags.FromKeystore)
+	keystoreDir := viper.GetString(flags.KeystoreDir)
+	groupThreshold := viper.GetInt(flags.GroupThreshold)
+	groupSpecs := viper.GetStringSlice(flags.Groups)
+	iterationExponent := viper.GetInt(flags.IterationExponent)
+	extendable := viper.GetBool(flags.Extendable)
+	useShamirPassphrase := viper.GetBool(flags.UseShamirPassphrase)
+
+	groups, err := parseShamirGroups(groupSpecs)
+	if err != nil {
+		return err
+	}
+
+	var seed []byte
+	if len(fromKeystore) > 0 {
+		if len(args) > 0 || inputHexSeed || usePassphrase {
+			return fmt.Errorf("mnemonic args, --%s or --%s cannot be used with --%s",
+				flags.InputHexSeed, flags.UsePassphrase, flags.FromKeystore)
+		}
+
+		seed, err = readKeystoreSeed(cmd, keystoreDir, fromKeystore)
+	} else {
+		seed, err = readSeed(cmd, args, inputHexSeed, usePassphrase, skipMnemonicValidation, language)
+	}
+	if err != nil {
+		return err
+	}
+
+	var passphrase string
+	if useShamirPassphrase {
+		passphrase, err = passphrases.New(cmd.OutOrStdout())
+		if err != nil {
+			return fmt.Errorf("failed to get shamir passphrase: %w", err)
+		}
+	}
+
+	mnemonics, err := slip39.Split(seed, &slip39.Config{
+		GroupThreshold:    groupThreshold,
+		Groups:            groups,
+		Passphrase:        passphrase,
+		IterationExponent: iterationExponent,
+		Extendable:        extendable,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to split seed into shares: %w", err)
+	}
+
+	var shares []shamirShare
+	for i, group := range mnemonics {
+		for j, mnemonic := range group {
+			shares = append(shares, shamirShare{Group: i + 1, Member: j + 1, Mnemonic: mnemonic})
+		}
+	}
+
+	return writeValue(cmd.OutOrStdout(), persistentFlags, shares)
+}
+
+func ShamirCombine(cmd *cobra.Command, args []string) error {
+	persistentFlags := getPersistentFlags(cmd)
+
+	_ = viper.BindPFlag(flags.UseShamirPassphrase, cmd.Flag(flags.UseShamirPassphrase))
+	_ = viper.BindPFlag(flags.DerivationPath, cmd.Flag(flags.DerivationPath))
+	_ = viper.BindPFlag(flags.Network, cmd.Flag(flags.Network))
+	_ = viper.BindPFlag(flags.AddrType, cmd.Flag(flags.AddrType))
+	_ = viper.BindPFlag(flags.Coin, cmd.Flag(flags.Coin))
+	_ = viper.BindPFlag(flags.ShowAllKeys, cmd.Flag(flags.ShowAllKeys))
+
+	useShamirPassphrase := viper.GetBool(flags.UseShamirPassphrase)
+	derivationPath := viper.GetString(flags.DerivationPath)
+	network := viper.GetString(flags.Network)
+	addrType := viper.GetString(flags.AddrType)
+	coin := viper.GetString(flags.Coin)
+	showAllKeys := viper.GetBool(flags.ShowAllKeys)
+
+	prompt, err := prompts.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get prompt status: %w", err)
+	}
+
+	mnemonics := args
+	if len(mnemonics) == 0 {
+		if prompt {
+			if _, err := fmt.Fprintln(cmd.OutOrStdout(), "Enter shares, one per line, followed by an empty line: "); err != nil {
+				return fmt.Errorf("failed to write to output: %w", err)
+			}
+		}
+
+		mnemonics, err = readShares(cmd, prompt)
+		if err != nil {
+			return err
+		}
+	}
+
+	var passphrase string
+	if useShamirPassphrase {
+		passphrase, err = passphrases.Prompt(cmd.OutOrStdout())
+		if err != nil {
+			return fmt.Errorf("failed to get shamir passphrase: %w", err)
+		}
+	}
+
+	seed, err := slip39.Combine(mnemonics, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to combine shares: %w", err)
+	}
+
+	key, err := keys.New(&keys.Config{
+		Seed:           seed,
+		Network:        network,
+		DerivationPath: derivationPath,
+		AddrType:       addrType,
+		Coin:           coin,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	columns := genColumns
+	if showAllKeys {
+		columns = genAllColumns
+	}
+
+	w, err := newKeyWriter(cmd.OutOrStdout(), persistentFlags, false, columns)
+	if err != nil {
+		return err
+	}
+
+	// show less information if not specifically asked,
+	// tabular output selects fields via columns instead
+	if !showAllKeys && !w.tabular() {
+		key = &keys.Key{
+			PrvKeyWif:  key.PrvKeyWif,
+			PrvKeyHex:  key.PrvKeyHex,
+			Addr:       key.Addr,
+			KeyOrigin:  key.KeyOrigin,
+			Descriptor: key.Descriptor,
+		}
+	}
+
+	if err := w.write(key); err != nil {
+		return err
+	}
+
+	return w.close()
+}
+
+// parseShamirGroups parses groups in the format of member threshold
+// of member count, such as 2of3
+func parseShamirGroups(specs []string) ([]slip39.Group, error) {
+	groups := make([]slip39.Group, len(specs))
+	for i, spec := range specs {
+		var group slip39.Group
+		if n, err := fmt.Sscanf(strings.ToLower(spec), "%dof%d", &group.MemberThreshold, &group.MemberCount); err != nil || n != 2 ||
+			fmt.Sprintf("%dof%d", group.MemberThreshold, group.MemberCount) != strings.ToLower(spec) {
+			return nil, fmt.Errorf("invalid group %s, needs to be member threshold of member count such as 2of3", spec)
+		}
+		groups[i] = group
+	}
+
+	return groups, nil
+}
+
+// readShares reads one share per line from input until EOF, or until
+// an empty line when prompting
+func readShares(cmd *cobra.Command, prompt bool) ([]string, error) {
+	var mnemonics []string
+	scanner := bufio.NewScanner(cmd.InOrStdin())
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			if prompt {
+				break
+			}
+			continue
+		}
+
+		mnemonics = append(mnemonics, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read shares from input: %w", err)
+	}
+
+	return mnemonics, nil
+}
@@ -0,0 +1,27 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/kubetrail/bip32/pkg/slip39"
+)
+
+func TestParseShamirGroups(t *testing.T) {
+	groups, err := parseShamirGroups([]string{"1of1", "2of3", "3OF5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []slip39.Group{{MemberThreshold: 1, MemberCount: 1}, {MemberThreshold: 2, MemberCount: 3}, {MemberThreshold: 3, MemberCount: 5}}
+	for i := range expected {
+		if groups[i] != expected[i] {
+			t.Fatal("expected", expected[i], ", got", groups[i])
+		}
+	}
+
+	for _, spec := range []string{"2-3", "2of", "of3", "2of3x", "2 of 3"} {
+		if _, err := parseShamirGroups([]string{spec}); err == nil {
+			t.Fatal("expected error for", spec)
+		}
+	}
+}
@@ -1,24 +1,153 @@
 package run
 
 import (
+	"encoding/hex"
+	"fmt"
 	"strings"
 
 	"github.com/kubetrail/bip32/pkg/flags"
+	"github.com/kubetrail/bip32/pkg/keys"
+	"github.com/kubetrail/bip39/pkg/mnemonics"
+	"github.com/kubetrail/bip39/pkg/passphrases"
+	"github.com/kubetrail/bip39/pkg/prompts"
+	"github.com/kubetrail/bip39/pkg/seeds"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 type persistentFlagValues struct {
-	OutputFormat string `json:"outputFormat,omitempty"`
+	OutputFormat string   `json:"outputFormat,omitempty"`
+	Columns      []string `json:"columns,omitempty"`
+	NoHeaders    bool     `json:"noHeaders,omitempty"`
 }
 
 func getPersistentFlags(cmd *cobra.Command) persistentFlagValues {
 	rootCmd := cmd.Root().PersistentFlags()
 
 	_ = viper.BindPFlag(flags.OutputFormat, rootCmd.Lookup(flags.OutputFormat))
+	_ = viper.BindPFlag(flags.Columns, rootCmd.Lookup(flags.Columns))
+	_ = viper.BindPFlag(flags.NoHeaders, rootCmd.Lookup(flags.NoHeaders))
 	outputFormat := strings.ToLower(viper.GetString(flags.OutputFormat))
+	columns := viper.GetStringSlice(flags.Columns)
+	noHeaders := viper.GetBool(flags.NoHeaders)
 
 	return persistentFlagValues{
 		OutputFormat: outputFormat,
+		Columns:      columns,
+		NoHeaders:    noHeaders,
 	}
 }
+
+// getScript returns witness script config for p2wsh and p2wsh-p2sh
+// address types. It returns nil if neither a witness script nor
+// a multisig template is provided via flags
+func getScript(cmd *cobra.Command) (*keys.Script, error) {
+	_ = viper.BindPFlag(flags.WitnessScript, cmd.Flag(flags.WitnessScript))
+	_ = viper.BindPFlag(flags.MultisigThreshold, cmd.Flag(flags.MultisigThreshold))
+	_ = viper.BindPFlag(flags.MultisigPubKeys, cmd.Flag(flags.MultisigPubKeys))
+
+	witnessScript := viper.GetString(flags.WitnessScript)
+	threshold := viper.GetInt(flags.MultisigThreshold)
+	pubKeys := viper.GetStringSlice(flags.MultisigPubKeys)
+
+	if len(witnessScript) == 0 && threshold == 0 && len(pubKeys) == 0 {
+		return nil, nil
+	}
+
+	script := &keys.Script{
+		Threshold: threshold,
+	}
+
+	if len(witnessScript) > 0 {
+		b, err := hex.DecodeString(witnessScript)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode witness script: %w", err)
+		}
+		script.WitnessScript = b
+	}
+
+	// cosigner keys can be hex encoded pub keys or extended keys, which
+	// are derived along the non-hardened derivation path of each key
+	for _, pubKey := range pubKeys {
+		if err := script.AddCosignerKey(pubKey); err != nil {
+			return nil, fmt.Errorf("failed to decode cosigner key %s: %w", pubKey, err)
+		}
+	}
+
+	return script, nil
+}
+
+// readSeed returns seed from mnemonic or hex seed provided as args,
+// or read from STDIN with a prompt if no args are provided
+func readSeed(cmd *cobra.Command, args []string, inputHexSeed, usePassphrase, skipMnemonicValidation bool, language string) ([]byte, error) {
+	prompt, err := prompts.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt status: %w", err)
+	}
+
+	if inputHexSeed && usePassphrase {
+		return nil, fmt.Errorf("cannot use passphrase when entering seed")
+	}
+
+	if inputHexSeed && skipMnemonicValidation {
+		return nil, fmt.Errorf("dont use --skip-mnemonic-validation when entering seed")
+	}
+
+	if inputHexSeed {
+		if len(args) > 0 {
+			seed, err := hex.DecodeString(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode seed: %w", err)
+			}
+
+			return seed, nil
+		}
+
+		if prompt {
+			if err := seeds.Prompt(cmd.OutOrStdout()); err != nil {
+				return nil, fmt.Errorf("failed to prompt for seed: %w", err)
+			}
+		}
+
+		seed, err := seeds.Read(cmd.InOrStdin())
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed: %w", err)
+		}
+
+		return seed, nil
+	}
+
+	var mnemonic string
+	if len(args) == 0 {
+		if prompt {
+			if err := mnemonics.Prompt(cmd.OutOrStdout()); err != nil {
+				return nil, fmt.Errorf("failed to write to output: %w", err)
+			}
+		}
+
+		mnemonic, err = mnemonics.Read(cmd.InOrStdin())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mnemonic from input: %w", err)
+		}
+	} else {
+		mnemonic = mnemonics.NewFromFields(args)
+	}
+
+	if !skipMnemonicValidation {
+		if mnemonic, err = mnemonics.Translate(mnemonic, language, mnemonics.LanguageEnglish); err != nil {
+			return nil, fmt.Errorf("failed to translate mnemonic to English, alternatively try --skip-mnemonic-validation flag: %w", err)
+		}
+	} else {
+		mnemonic = mnemonics.Tidy(mnemonic)
+	}
+
+	var passphrase string
+	if usePassphrase {
+		passphrase, err = passphrases.New(cmd.OutOrStdout())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get passphrase: %w", err)
+		}
+	}
+
+	return seeds.New(mnemonic, passphrase), nil
+}
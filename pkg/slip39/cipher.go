@@ -0,0 +1,78 @@
+package slip39
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// roundFunction is the Feistel round function, which is PBKDF2-HMAC-SHA256
+// of the round index and the passphrase salted with the right half
+func roundFunction(i int, passphrase []byte, iterationExponent int, salt, r []byte) []byte {
+	iterations := (baseIterationCount << uint(iterationExponent)) / roundCount
+	password := append([]byte{byte(i)}, passphrase...)
+
+	return pbkdf2.Key(password, append(append([]byte{}, salt...), r...), iterations, len(r), sha256.New)
+}
+
+// cipherSalt returns salt of the round function, which binds the
+// encryption to the identifier for non-extendable shares
+func cipherSalt(identifier int, extendable bool) []byte {
+	if extendable {
+		return nil
+	}
+
+	return append([]byte(customizationString), byte(identifier>>8), byte(identifier))
+}
+
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+
+	return out
+}
+
+// feistel runs the rounds of the 4 round Feistel network in the order of
+// round indices and returns the halves swapped
+func feistel(data []byte, passphrase string, iterationExponent, identifier int, extendable bool, rounds []int) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("length of master secret in bytes must be an even number")
+	}
+
+	if err := validatePassphrase(passphrase); err != nil {
+		return nil, err
+	}
+
+	salt := cipherSalt(identifier, extendable)
+	l, r := data[:len(data)/2], data[len(data)/2:]
+	for _, i := range rounds {
+		l, r = r, xorBytes(l, roundFunction(i, []byte(passphrase), iterationExponent, salt, r))
+	}
+
+	return append(append([]byte{}, r...), l...), nil
+}
+
+// encrypt encrypts master secret with the passphrase
+func encrypt(masterSecret []byte, passphrase string, iterationExponent, identifier int, extendable bool) ([]byte, error) {
+	return feistel(masterSecret, passphrase, iterationExponent, identifier, extendable, []int{0, 1, 2, 3})
+}
+
+// decrypt decrypts encrypted master secret with the passphrase
+func decrypt(encryptedMasterSecret []byte, passphrase string, iterationExponent, identifier int, extendable bool) ([]byte, error) {
+	return feistel(encryptedMasterSecret, passphrase, iterationExponent, identifier, extendable, []int{3, 2, 1, 0})
+}
+
+// validatePassphrase checks that passphrase consists of printable
+// ASCII characters as required by SLIP-39
+func validatePassphrase(passphrase string) error {
+	for _, c := range []byte(passphrase) {
+		if c < 32 || c > 126 {
+			return fmt.Errorf("passphrase must consist of printable ASCII characters")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package slip39
+
+// rs1024Gen is the generator of RS1024 checksum, which is a Reed-Solomon
+// code over GF(1024) that detects any error affecting at most 3 words
+var rs1024Gen = []int{
+	0xe0e040, 0x1c1c080, 0x3838100, 0x7070200, 0xe0e0009,
+	0x1c0c2412, 0x38086c24, 0x3090fc48, 0x21b1f890, 0x3f3f120,
+}
+
+func rs1024Polymod(values []int) int {
+	chk := 1
+	for _, v := range values {
+		b := chk >> 20
+		chk = (chk&0xfffff)<<10 ^ v
+		for i := 0; i < 10; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= rs1024Gen[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+// customizationValues returns customization string as checksum input
+func customizationValues(extendable bool) []int {
+	s := customizationString
+	if extendable {
+		s = customizationStringExtendable
+	}
+
+	values := make([]int, len(s))
+	for i := range s {
+		values[i] = int(s[i])
+	}
+
+	return values
+}
+
+// rs1024CreateChecksum returns checksum words of the data words
+func rs1024CreateChecksum(data []int, extendable bool) []int {
+	values := append(customizationValues(extendable), data...)
+	values = append(values, make([]int, checksumLengthWords)...)
+	polymod := rs1024Polymod(values) ^ 1
+
+	checksum := make([]int, checksumLengthWords)
+	for i := range checksum {
+		checksum[i] = (polymod >> uint(radixBits*(checksumLengthWords-1-i))) & (1<<radixBits - 1)
+	}
+
+	return checksum
+}
+
+// rs1024VerifyChecksum returns true if data words end with valid checksum
+func rs1024VerifyChecksum(data []int, extendable bool) bool {
+	return rs1024Polymod(append(customizationValues(extendable), data...)) == 1
+}
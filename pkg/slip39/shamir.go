@@ -0,0 +1,184 @@
+package slip39
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// randReader is the source of random share values and identifiers
+var randReader io.Reader = rand.Reader
+
+// exp and log tables of GF(256) with the Rijndael polynomial
+// x^8 + x^4 + x^3 + x + 1 and the generator x + 1
+var gfExp, gfLog [256]int
+
+func init() {
+	poly := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = poly
+		gfLog[poly] = i
+
+		// multiply by x + 1 and reduce by the Rijndael polynomial
+		poly = (poly << 1) ^ poly
+		if poly&0x100 != 0 {
+			poly ^= 0x11b
+		}
+	}
+}
+
+// rawShare is a point of the polynomial, i.e., share value at index x
+type rawShare struct {
+	x    int
+	data []byte
+}
+
+// interpolate evaluates at x the polynomial passing through the shares
+// using Lagrange interpolation over GF(256)
+func interpolate(shares []rawShare, x int) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("invalid set of shares, share list is empty")
+	}
+
+	seen := make(map[int]bool, len(shares))
+	for _, share := range shares {
+		if seen[share.x] {
+			return nil, fmt.Errorf("invalid set of shares, share indices must be unique")
+		}
+		seen[share.x] = true
+
+		if len(share.data) != len(shares[0].data) {
+			return nil, fmt.Errorf("invalid set of shares, all share values must have the same length")
+		}
+	}
+
+	for _, share := range shares {
+		if share.x == x {
+			return append([]byte{}, share.data...), nil
+		}
+	}
+
+	logProd := 0
+	for _, share := range shares {
+		logProd += gfLog[share.x^x]
+	}
+
+	result := make([]byte, len(shares[0].data))
+	for _, share := range shares {
+		logBasisEval := logProd - gfLog[share.x^x]
+		for _, other := range shares {
+			logBasisEval -= gfLog[share.x^other.x]
+		}
+		logBasisEval = ((logBasisEval % 255) + 255) % 255
+
+		for i, v := range share.data {
+			if v != 0 {
+				result[i] ^= byte(gfExp[(gfLog[v]+logBasisEval)%255])
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// createDigest returns first 4 bytes of HMAC-SHA256 of the shared secret
+// keyed with the random part of the digest share
+func createDigest(randomData, sharedSecret []byte) []byte {
+	mac := hmac.New(sha256.New, randomData)
+	mac.Write(sharedSecret)
+
+	return mac.Sum(nil)[:digestLengthBytes]
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(randReader, b); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+
+	return b, nil
+}
+
+// splitSecret splits shared secret into share count shares such that
+// any threshold of them recover the secret. Digest share at index 254
+// allows verification of the recovered secret
+func splitSecret(threshold, shareCount int, sharedSecret []byte) ([]rawShare, error) {
+	if threshold < 1 {
+		return nil, fmt.Errorf("threshold must be a positive integer")
+	}
+
+	if threshold > shareCount {
+		return nil, fmt.Errorf("threshold must not exceed the number of shares")
+	}
+
+	if shareCount > maxShareCount {
+		return nil, fmt.Errorf("number of shares must not exceed %d", maxShareCount)
+	}
+
+	shares := make([]rawShare, 0, shareCount)
+	if threshold == 1 {
+		for i := 0; i < shareCount; i++ {
+			shares = append(shares, rawShare{x: i, data: append([]byte{}, sharedSecret...)})
+		}
+
+		return shares, nil
+	}
+
+	randomShareCount := threshold - 2
+	for i := 0; i < randomShareCount; i++ {
+		data, err := randomBytes(len(sharedSecret))
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, rawShare{x: i, data: data})
+	}
+
+	randomPart, err := randomBytes(len(sharedSecret) - digestLengthBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := createDigest(randomPart, sharedSecret)
+	baseShares := append(append([]rawShare{}, shares...),
+		rawShare{x: digestIndex, data: append(digest, randomPart...)},
+		rawShare{x: secretIndex, data: sharedSecret},
+	)
+
+	for i := randomShareCount; i < shareCount; i++ {
+		data, err := interpolate(baseShares, i)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, rawShare{x: i, data: data})
+	}
+
+	return shares, nil
+}
+
+// recoverSecret recovers shared secret from threshold shares
+// and verifies it against the digest share
+func recoverSecret(threshold int, shares []rawShare) ([]byte, error) {
+	if threshold == 1 {
+		return append([]byte{}, shares[0].data...), nil
+	}
+
+	sharedSecret, err := interpolate(shares, secretIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	digestShare, err := interpolate(shares, digestIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, randomPart := digestShare[:digestLengthBytes], digestShare[digestLengthBytes:]
+	if !bytes.Equal(digest, createDigest(randomPart, sharedSecret)) {
+		return nil, fmt.Errorf("invalid digest of the shared secret")
+	}
+
+	return sharedSecret, nil
+}
@@ -0,0 +1,145 @@
+package slip39
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// share is a SLIP-39 share along with parameters encoded in its mnemonic
+type share struct {
+	identifier        int
+	extendable        bool
+	iterationExponent int
+	groupIndex        int
+	groupThreshold    int
+	groupCount        int
+	memberIndex       int
+	memberThreshold   int
+	value             []byte
+}
+
+// commonParams are parameters that must match across all shares
+type commonParams struct {
+	identifier        int
+	extendable        bool
+	iterationExponent int
+	groupThreshold    int
+	groupCount        int
+}
+
+func (s *share) commonParams() commonParams {
+	return commonParams{
+		identifier:        s.identifier,
+		extendable:        s.extendable,
+		iterationExponent: s.iterationExponent,
+		groupThreshold:    s.groupThreshold,
+		groupCount:        s.groupCount,
+	}
+}
+
+// intToIndices splits value into n words of radix bits, most
+// significant word first
+func intToIndices(value *big.Int, n int) []int {
+	mask := big.NewInt(1<<radixBits - 1)
+	indices := make([]int, n)
+	for i := range indices {
+		shift := uint(radixBits * (n - 1 - i))
+		indices[i] = int(new(big.Int).And(new(big.Int).Rsh(value, shift), mask).Int64())
+	}
+
+	return indices
+}
+
+// intFromIndices joins words of radix bits into an integer
+func intFromIndices(indices []int) *big.Int {
+	value := new(big.Int)
+	for _, idx := range indices {
+		value.Lsh(value, radixBits)
+		value.Or(value, big.NewInt(int64(idx)))
+	}
+
+	return value
+}
+
+// mnemonic encodes the share as mnemonic words
+func (s *share) mnemonic() string {
+	idExp := s.identifier<<(iterationExpLengthBits+extendableFlagLengthBits) | s.iterationExponent
+	if s.extendable {
+		idExp |= 1 << iterationExpLengthBits
+	}
+
+	groupParams := s.groupIndex
+	for _, v := range []int{s.groupThreshold - 1, s.groupCount - 1, s.memberIndex, s.memberThreshold - 1} {
+		groupParams = groupParams<<4 | v
+	}
+
+	valueWordCount := (len(s.value)*8 + radixBits - 1) / radixBits
+
+	data := intToIndices(big.NewInt(int64(idExp)), idExpLengthWords)
+	data = append(data, intToIndices(big.NewInt(int64(groupParams)), 2)...)
+	data = append(data, intToIndices(new(big.Int).SetBytes(s.value), valueWordCount)...)
+	data = append(data, rs1024CreateChecksum(data, s.extendable)...)
+
+	return indicesToMnemonic(data)
+}
+
+// parseShare decodes share from its mnemonic words
+func parseShare(mnemonic string) (*share, error) {
+	data, err := mnemonicToIndices(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < minMnemonicLengthWords {
+		return nil, fmt.Errorf("invalid mnemonic length, must be at least %d words", minMnemonicLengthWords)
+	}
+
+	paddingLen := (radixBits * (len(data) - metadataLengthWords)) % 16
+	if paddingLen > 8 {
+		return nil, fmt.Errorf("invalid mnemonic length")
+	}
+
+	idExp := int(intFromIndices(data[:idExpLengthWords]).Int64())
+	s := &share{
+		identifier:        idExp >> (iterationExpLengthBits + extendableFlagLengthBits),
+		extendable:        (idExp>>iterationExpLengthBits)&1 == 1,
+		iterationExponent: idExp & (1<<iterationExpLengthBits - 1),
+	}
+
+	if !rs1024VerifyChecksum(data, s.extendable) {
+		return nil, fmt.Errorf("invalid mnemonic checksum for %q", mnemonicPrefix(mnemonic))
+	}
+
+	groupParams := int(intFromIndices(data[idExpLengthWords : idExpLengthWords+2]).Int64())
+	s.groupIndex = groupParams >> 16
+	s.groupThreshold = (groupParams>>12)&0xf + 1
+	s.groupCount = (groupParams>>8)&0xf + 1
+	s.memberIndex = (groupParams >> 4) & 0xf
+	s.memberThreshold = groupParams&0xf + 1
+
+	if s.groupCount < s.groupThreshold {
+		return nil, fmt.Errorf("invalid mnemonic %q, group threshold cannot be greater than group count", mnemonicPrefix(mnemonic))
+	}
+
+	valueData := data[idExpLengthWords+2 : len(data)-checksumLengthWords]
+	valueByteCount := (radixBits*len(valueData) - paddingLen) / 8
+	value := intFromIndices(valueData)
+	if value.BitLen() > valueByteCount*8 {
+		return nil, fmt.Errorf("invalid mnemonic padding for %q", mnemonicPrefix(mnemonic))
+	}
+
+	s.value = value.FillBytes(make([]byte, valueByteCount))
+
+	return s, nil
+}
+
+// mnemonicPrefix returns first words of the mnemonic, which identify
+// the share in error messages without revealing it
+func mnemonicPrefix(mnemonic string) string {
+	data, err := mnemonicToIndices(mnemonic)
+	if err != nil || len(data) < idExpLengthWords+2 {
+		return ""
+	}
+
+	return indicesToMnemonic(data[:idExpLengthWords+2])
+}
@@ -0,0 +1,226 @@
+// Package slip39 implements Shamir's secret-sharing for mnemonic codes per
+// https://github.com/satoshilabs/slips/blob/master/slip-0039.md
+//
+// Master secret is encrypted with a passphrase and split into groups of
+// member shares, each encoded as a mnemonic of the SLIP-39 wordlist. The
+// master secret is recovered from a threshold of member shares in each
+// of a threshold of groups and is used as the BIP-32 seed
+package slip39
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	radixBits                = 10
+	idLengthBits             = 15
+	extendableFlagLengthBits = 1
+	iterationExpLengthBits   = 4
+	idExpLengthWords         = 2
+	checksumLengthWords      = 3
+	digestLengthBytes        = 4
+	metadataLengthWords      = idExpLengthWords + 2 + checksumLengthWords
+	minStrengthBits          = 128
+	minMnemonicLengthWords   = metadataLengthWords + (minStrengthBits+radixBits-1)/radixBits
+	baseIterationCount       = 10000
+	roundCount               = 4
+	maxShareCount            = 16
+	maxIterationExponent     = 1<<iterationExpLengthBits - 1
+	secretIndex              = 255
+	digestIndex              = 254
+
+	customizationString           = "shamir"
+	customizationStringExtendable = "shamir_extendable"
+)
+
+// Group describes member shares of a group, any member threshold
+// of which recover the group share
+type Group struct {
+	MemberThreshold int
+	MemberCount     int
+}
+
+// Config describes the split of master secret into shares
+type Config struct {
+	// GroupThreshold is the number of groups required to recover
+	// the master secret
+	GroupThreshold int
+	Groups         []Group
+	// Passphrase encrypts master secret and is required for recovery.
+	// Recovery with a different passphrase produces a different secret
+	Passphrase string
+	// IterationExponent sets PBKDF2 iterations of the encryption
+	// to 10000 x 2^IterationExponent
+	IterationExponent int
+	// Extendable shares allow splitting the same master secret again
+	// with a different identifier and are compatible with each other
+	Extendable bool
+}
+
+// Split encrypts master secret with the passphrase and splits it into
+// mnemonic shares per groups of the config. Mnemonics are returned
+// per group in the order of groups
+func Split(masterSecret []byte, config *Config) ([][]string, error) {
+	if len(masterSecret)*8 < minStrengthBits {
+		return nil, fmt.Errorf("length of master secret must be at least %d bits", minStrengthBits)
+	}
+
+	if len(masterSecret)%2 != 0 {
+		return nil, fmt.Errorf("length of master secret in bytes must be an even number")
+	}
+
+	if config.IterationExponent < 0 || config.IterationExponent > maxIterationExponent {
+		return nil, fmt.Errorf("iteration exponent must be between 0 and %d", maxIterationExponent)
+	}
+
+	if len(config.Groups) == 0 {
+		return nil, fmt.Errorf("at least one group is required")
+	}
+
+	if config.GroupThreshold > len(config.Groups) {
+		return nil, fmt.Errorf("group threshold must not exceed the number of groups")
+	}
+
+	for _, group := range config.Groups {
+		if group.MemberThreshold == 1 && group.MemberCount > 1 {
+			return nil, fmt.Errorf("creating multiple member shares with member threshold 1 is not allowed, use 1-of-1 member sharing instead")
+		}
+	}
+
+	id, err := randomBytes(2)
+	if err != nil {
+		return nil, err
+	}
+	identifier := int(binary.BigEndian.Uint16(id)) & (1<<idLengthBits - 1)
+
+	ciphertext, err := encrypt(masterSecret, config.Passphrase, config.IterationExponent, identifier, config.Extendable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt master secret: %w", err)
+	}
+
+	groupShares, err := splitSecret(config.GroupThreshold, len(config.Groups), ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split master secret into groups: %w", err)
+	}
+
+	mnemonics := make([][]string, len(groupShares))
+	for i, groupShare := range groupShares {
+		group := config.Groups[i]
+		memberShares, err := splitSecret(group.MemberThreshold, group.MemberCount, groupShare.data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split group %d into member shares: %w", i+1, err)
+		}
+
+		for _, memberShare := range memberShares {
+			s := &share{
+				identifier:        identifier,
+				extendable:        config.Extendable,
+				iterationExponent: config.IterationExponent,
+				groupIndex:        groupShare.x,
+				groupThreshold:    config.GroupThreshold,
+				groupCount:        len(config.Groups),
+				memberIndex:       memberShare.x,
+				memberThreshold:   group.MemberThreshold,
+				value:             memberShare.data,
+			}
+
+			mnemonics[i] = append(mnemonics[i], s.mnemonic())
+		}
+	}
+
+	return mnemonics, nil
+}
+
+// Combine recovers master secret from mnemonic shares, which need to be
+// exactly a member threshold of shares from each of a group threshold of
+// groups, and decrypts it with the passphrase
+func Combine(mnemonics []string, passphrase string) ([]byte, error) {
+	if len(mnemonics) == 0 {
+		return nil, fmt.Errorf("the set of shares is empty")
+	}
+
+	var params commonParams
+	groups := make(map[int][]*share)
+	seen := make(map[string]bool)
+	for i, mnemonic := range mnemonics {
+		mnemonic = strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+		if seen[mnemonic] {
+			continue
+		}
+		seen[mnemonic] = true
+
+		s, err := parseShare(mnemonic)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			params = s.commonParams()
+		} else if s.commonParams() != params {
+			return nil, fmt.Errorf("invalid set of mnemonics, all mnemonics must share identifier, iteration exponent and group parameters")
+		}
+
+		if group := groups[s.groupIndex]; len(group) > 0 && group[0].memberThreshold != s.memberThreshold {
+			return nil, fmt.Errorf("invalid set of mnemonics, all mnemonics in a group must have the same member threshold")
+		}
+		groups[s.groupIndex] = append(groups[s.groupIndex], s)
+	}
+
+	if len(groups) < params.groupThreshold {
+		return nil, fmt.Errorf("insufficient number of mnemonic groups, the required number of groups is %d", params.groupThreshold)
+	}
+
+	if len(groups) != params.groupThreshold {
+		return nil, fmt.Errorf("wrong number of mnemonic groups, expected %d groups, but %d were provided", params.groupThreshold, len(groups))
+	}
+
+	groupIndices := make([]int, 0, len(groups))
+	for groupIndex := range groups {
+		groupIndices = append(groupIndices, groupIndex)
+	}
+	sort.Ints(groupIndices)
+
+	groupShares := make([]rawShare, 0, len(groups))
+	for _, groupIndex := range groupIndices {
+		group := groups[groupIndex]
+		memberThreshold := group[0].memberThreshold
+		if len(group) < memberThreshold {
+			return nil, fmt.Errorf("insufficient number of mnemonics in group %d, the required number of mnemonics is %d", groupIndex+1, memberThreshold)
+		}
+
+		if len(group) != memberThreshold {
+			return nil, fmt.Errorf("wrong number of mnemonics in group %d, expected %d mnemonics, but %d were provided", groupIndex+1, memberThreshold, len(group))
+		}
+
+		memberShares := make([]rawShare, len(group))
+		for i, s := range group {
+			memberShares[i] = rawShare{x: s.memberIndex, data: s.value}
+		}
+
+		data, err := recoverSecret(memberThreshold, memberShares)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recover group %d share: %w", groupIndex+1, err)
+		}
+
+		groupShares = append(groupShares, rawShare{x: groupIndex, data: data})
+	}
+
+	ciphertext, err := recoverSecret(params.groupThreshold, groupShares)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover encrypted master secret: %w", err)
+	}
+
+	if len(ciphertext)*8 < minStrengthBits {
+		return nil, fmt.Errorf("length of master secret must be at least %d bits", minStrengthBits)
+	}
+
+	masterSecret, err := decrypt(ciphertext, passphrase, params.iterationExponent, params.identifier, params.extendable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt master secret: %w", err)
+	}
+
+	return masterSecret, nil
+}
@@ -0,0 +1,157 @@
+package slip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubetrail/bip32/pkg/keys"
+)
+
+// TestCombine_Vectors checks test vectors of the SLIP-39 reference
+// implementation, which are copied to test folder from
+// https://github.com/trezor/python-shamir-mnemonic/blob/master/vectors.json
+// Each vector lists mnemonics, master secret and the BIP-32 master key
+// using passphrase TREZOR, or empty master secret for invalid mnemonics
+func TestCombine_Vectors(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("..", "..", "test", "slip39-vectors.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vectors [][]interface{}
+	if err := json.Unmarshal(b, &vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vectors) != 45 {
+		t.Fatal("expected 45 vectors, got", len(vectors))
+	}
+
+	for _, vector := range vectors {
+		description := vector[0].(string)
+		var mnemonics []string
+		for _, mnemonic := range vector[1].([]interface{}) {
+			mnemonics = append(mnemonics, mnemonic.(string))
+		}
+		masterSecretHex, xPrv := vector[2].(string), vector[3].(string)
+
+		masterSecret, err := Combine(mnemonics, "TREZOR")
+		if len(masterSecretHex) == 0 {
+			if err == nil {
+				t.Fatal("expected error for", description)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(description, err)
+		}
+
+		if hex.EncodeToString(masterSecret) != masterSecretHex {
+			t.Fatal("expected", masterSecretHex, ", got", hex.EncodeToString(masterSecret), ", for", description)
+		}
+
+		// recovered master secret is the BIP-32 seed
+		key, err := keys.New(&keys.Config{
+			Seed:           masterSecret,
+			Network:        keys.NetworkTypeMainnet,
+			DerivationPath: "m",
+			AddrType:       keys.AddrTypeP2pkhOrP2sh,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.XPrv != xPrv {
+			t.Fatal("expected", xPrv, ", got", key.XPrv, ", for", description)
+		}
+	}
+}
+
+func TestSplit_Combine(t *testing.T) {
+	masterSecret, err := hex.DecodeString("b43ceb7e57a0ea8766221624d01b0864ef0e6a0e3f4cd0ff3a3e1de43f93bc2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, extendable := range []bool{false, true} {
+		mnemonics, err := Split(masterSecret, &Config{
+			GroupThreshold:    2,
+			Groups:            []Group{{1, 1}, {2, 3}, {3, 5}},
+			Passphrase:        "secret",
+			IterationExponent: 0,
+			Extendable:        extendable,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(mnemonics) != 3 || len(mnemonics[0]) != 1 || len(mnemonics[1]) != 3 || len(mnemonics[2]) != 5 {
+			t.Fatal("unexpected number of shares", mnemonics)
+		}
+
+		for _, subset := range [][]string{
+			{mnemonics[0][0], mnemonics[1][0], mnemonics[1][2]},
+			{mnemonics[2][4], mnemonics[1][1], mnemonics[2][0], mnemonics[2][2], mnemonics[1][2]},
+		} {
+			recovered, err := Combine(subset, "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(recovered, masterSecret) {
+				t.Fatal("expected", hex.EncodeToString(masterSecret), ", got", hex.EncodeToString(recovered))
+			}
+
+			// passphrase is not verified and recovers a different secret
+			recovered, err = Combine(subset, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if bytes.Equal(recovered, masterSecret) {
+				t.Fatal("expected different master secret for a different passphrase")
+			}
+		}
+
+		for _, subset := range [][]string{
+			{mnemonics[1][0], mnemonics[1][2]},
+			{mnemonics[0][0], mnemonics[1][0]},
+			{mnemonics[0][0], mnemonics[1][0], mnemonics[1][1], mnemonics[1][2]},
+		} {
+			if _, err := Combine(subset, "secret"); err == nil {
+				t.Fatal("expected error for insufficient or excess shares")
+			}
+		}
+	}
+}
+
+func TestSplit_Invalid(t *testing.T) {
+	masterSecret := make([]byte, 16)
+
+	tests := []struct {
+		masterSecret []byte
+		config       *Config
+	}{
+		{masterSecret: make([]byte, 14), config: &Config{GroupThreshold: 1, Groups: []Group{{2, 3}}}},
+		{masterSecret: make([]byte, 17), config: &Config{GroupThreshold: 1, Groups: []Group{{2, 3}}}},
+		{masterSecret: masterSecret, config: &Config{GroupThreshold: 2, Groups: []Group{{2, 3}}}},
+		{masterSecret: masterSecret, config: &Config{GroupThreshold: 1, Groups: []Group{{1, 3}}}},
+		{masterSecret: masterSecret, config: &Config{GroupThreshold: 1, Groups: []Group{{4, 3}}}},
+		{masterSecret: masterSecret, config: &Config{GroupThreshold: 1, Groups: []Group{{2, 17}}}},
+		{masterSecret: masterSecret, config: &Config{GroupThreshold: 0, Groups: []Group{{2, 3}}}},
+		{masterSecret: masterSecret, config: &Config{GroupThreshold: 1}},
+		{masterSecret: masterSecret, config: &Config{GroupThreshold: 1, Groups: []Group{{2, 3}}, IterationExponent: 16}},
+		{masterSecret: masterSecret, config: &Config{GroupThreshold: 1, Groups: []Group{{2, 3}}, Passphrase: "ü"}},
+	}
+
+	for i, test := range tests {
+		if _, err := Split(test.masterSecret, test.config); err == nil {
+			t.Fatal("expected error for test", i)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package slip39
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+// wordlistText is the SLIP-39 wordlist of 1024 words per
+// https://github.com/satoshilabs/slips/blob/master/slip-0039/wordlist.txt
+//
+//go:embed wordlist.txt
+var wordlistText string
+
+var (
+	wordlist  []string
+	wordIndex map[string]int
+)
+
+func init() {
+	wordlist = strings.Fields(wordlistText)
+	if len(wordlist) != 1<<radixBits {
+		panic(fmt.Sprintf("invalid slip39 wordlist length %d", len(wordlist)))
+	}
+
+	wordIndex = make(map[string]int, len(wordlist))
+	for i, word := range wordlist {
+		wordIndex[word] = i
+	}
+}
+
+// mnemonicToIndices returns wordlist indices of mnemonic words
+func mnemonicToIndices(mnemonic string) ([]int, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	indices := make([]int, len(words))
+	for i, word := range words {
+		idx, ok := wordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("invalid mnemonic word %q", word)
+		}
+		indices[i] = idx
+	}
+
+	return indices, nil
+}
+
+// indicesToMnemonic returns mnemonic words of wordlist indices
+func indicesToMnemonic(indices []int) string {
+	words := make([]string, len(indices))
+	for i, idx := range indices {
+		words[i] = wordlist[idx]
+	}
+
+	return strings.Join(words, " ")
+}